@@ -0,0 +1,243 @@
+package npapi
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AnomalyKind classifies hashrate anomalies.
+type AnomalyKind int
+
+const (
+	// SuddenDrop is a hashrate far below the recent baseline
+	SuddenDrop AnomalyKind = iota
+	// Degradation is a slow but steady hashrate decline
+	Degradation
+	// Flapping is a worker repeatedly going on- and offline
+	Flapping
+)
+
+func (k AnomalyKind) String() string {
+	switch k {
+	case SuddenDrop:
+		return "sudden drop"
+	case Degradation:
+		return "degradation"
+	case Flapping:
+		return "flapping"
+	}
+	return "unknown"
+}
+
+// Anomaly is an interval of anomalous worker hashrate.
+type Anomaly struct {
+	// Worker ID
+	Worker string
+	// Anomaly kind
+	Kind AnomalyKind
+	// Anomalous interval
+	Start, End time.Time
+	// Relative hashrate loss compared to the baseline, or the flap rate for flapping
+	Severity float64
+}
+
+// AnomalyConfig tunes the anomaly detection. Windows are given in history items.
+type AnomalyConfig struct {
+	// Trailing items forming the baseline of a robust z-score
+	BaselineWindow int
+	// Robust z-score below which an item counts as a sudden drop
+	DropThreshold float64
+	// Minimum relative hashrate loss of a sudden drop, ignoring outliers of very steady workers
+	DropSeverity float64
+	// Items over which a steady decline is measured
+	DegradationWindow int
+	// Relative decline over the degradation window counting as degradation
+	DegradationThreshold float64
+	// Items in which on/off transitions are counted
+	FlapWindow int
+	// Number of transitions within the flap window counting as flapping
+	FlapTransitions int
+}
+
+// DefaultAnomalyConfig is tuned for hourly worker hashrate histories.
+var DefaultAnomalyConfig = AnomalyConfig{
+	BaselineWindow:       24,
+	DropThreshold:        3.5,
+	DropSeverity:         0.2,
+	DegradationWindow:    48,
+	DegradationThreshold: 0.1,
+	FlapWindow:           12,
+	FlapTransitions:      4,
+}
+
+// median returns the median of the values.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentile(sorted, 0.5)
+}
+
+// stepLike reports whether the values are explained much better by a single level shift than by the linear fit,
+// as for a sudden drop rather than a steady decline.
+func stepLike(xs, ys []float64, slope, intercept float64) bool {
+	var linear float64
+	for i, x := range xs {
+		r := ys[i] - (slope*x + intercept)
+		linear += r * r
+	}
+	// prefix sums give the squared error of the best two-level fit for every split
+	n := len(ys)
+	sums, squares := make([]float64, n+1), make([]float64, n+1)
+	for i, y := range ys {
+		sums[i+1], squares[i+1] = sums[i]+y, squares[i]+y*y
+	}
+	sse := func(from, to int) float64 {
+		sum, count := sums[to]-sums[from], float64(to-from)
+		return squares[to] - squares[from] - sum*sum/count
+	}
+	best := math.Inf(1)
+	for k := 1; k < n; k++ {
+		best = math.Min(best, sse(0, k)+sse(k, n))
+	}
+	return best < 0.5*linear
+}
+
+// mergeAnomalies joins overlapping or adjacent anomalies of the same kind, keeping the highest severity.
+func mergeAnomalies(anomalies []Anomaly) []Anomaly {
+	var merged []Anomaly
+	for _, a := range anomalies {
+		if n := len(merged); n > 0 && merged[n-1].Kind == a.Kind && !a.Start.After(merged[n-1].End) {
+			last := &merged[n-1]
+			if a.End.After(last.End) {
+				last.End = a.End
+			}
+			last.Severity = math.Max(last.Severity, a.Severity)
+			continue
+		}
+		merged = append(merged, a)
+	}
+	return merged
+}
+
+// DetectAnomalies flags sudden drops, slow degradation and flapping in the hashrate history of a worker.
+func DetectAnomalies(worker string, history []HistoryItem, config AnomalyConfig) []Anomaly {
+	points := HistoryPoints(history)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	var drops, degradations, flaps []Anomaly
+	// sudden drops: robust z-score against the trailing baseline
+	lastDrop := -2
+	dropped := make(map[int]bool)
+	for i := config.BaselineWindow; config.BaselineWindow > 0 && i < len(points); i++ {
+		window := make([]float64, config.BaselineWindow)
+		for j := range window {
+			window[j] = points[i-config.BaselineWindow+j].Value
+		}
+		m := median(window)
+		deviations := make([]float64, len(window))
+		for j, v := range window {
+			deviations[j] = math.Abs(v - m)
+		}
+		mad := median(deviations)
+		if m <= 0 {
+			continue
+		}
+		var z float64
+		if mad > 0 {
+			z = 0.6745 * (points[i].Value - m) / mad
+		} else if points[i].Value < m {
+			z = math.Inf(-1)
+		}
+		if severity := 1 - points[i].Value/m; z < -config.DropThreshold && severity >= config.DropSeverity {
+			dropped[i] = true
+			if n := len(drops); n > 0 && i == lastDrop+1 {
+				// consecutive drops form a single interval
+				drops[n-1].End = points[i].Date
+				drops[n-1].Severity = math.Max(drops[n-1].Severity, severity)
+				lastDrop = i
+				continue
+			}
+			lastDrop = i
+			drops = append(drops, Anomaly{
+				Worker:   worker,
+				Kind:     SuddenDrop,
+				Start:    points[i].Date,
+				End:      points[i].Date,
+				Severity: severity,
+			})
+		}
+	}
+	// degradation: relative decline of a linear fit over the window, ignoring sudden drops and level shifts
+	for i := config.DegradationWindow; config.DegradationWindow > 1 && i <= len(points); i++ {
+		window := points[i-config.DegradationWindow : i]
+		var xs, ys []float64
+		for j, p := range window {
+			if !dropped[i-config.DegradationWindow+j] {
+				xs, ys = append(xs, float64(j)), append(ys, p.Value)
+			}
+		}
+		if len(xs) < len(window)/2 {
+			continue
+		}
+		slope, intercept := linearRegression(xs, ys)
+		if intercept <= 0 {
+			continue
+		}
+		decline := -slope * float64(len(window)-1) / intercept
+		if decline > config.DegradationThreshold && !stepLike(xs, ys, slope, intercept) {
+			degradations = append(degradations, Anomaly{
+				Worker:   worker,
+				Kind:     Degradation,
+				Start:    window[0].Date,
+				End:      window[len(window)-1].Date,
+				Severity: decline,
+			})
+		}
+	}
+	// flapping: on/off transitions relative to half of the overall median
+	if len(points) > 0 && config.FlapWindow > 1 {
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.Value
+		}
+		threshold := median(values) / 2
+		for i := config.FlapWindow; i <= len(points); i++ {
+			window := points[i-config.FlapWindow : i]
+			transitions := 0
+			for j := 1; j < len(window); j++ {
+				if (window[j].Value > threshold) != (window[j-1].Value > threshold) {
+					transitions++
+				}
+			}
+			if transitions >= config.FlapTransitions {
+				flaps = append(flaps, Anomaly{
+					Worker:   worker,
+					Kind:     Flapping,
+					Start:    window[0].Date,
+					End:      window[len(window)-1].Date,
+					Severity: float64(transitions) / float64(len(window)-1),
+				})
+			}
+		}
+	}
+	anomalies := append(append(mergeAnomalies(drops), mergeAnomalies(degradations)...), mergeAnomalies(flaps)...)
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Start.Before(anomalies[j].Start) })
+	return anomalies
+}
+
+// WorkerAnomalies fetches the hashrate history of every worker of the account and detects anomalies.
+func WorkerAnomalies(addr string, config AnomalyConfig) ([]Anomaly, error) {
+	workers, err := Workers(addr)
+	if err != nil {
+		return nil, err
+	}
+	var anomalies []Anomaly
+	for _, w := range workers {
+		history, err := WorkerHashrateHistory(addr, w.ID)
+		if err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, DetectAnomalies(w.ID, history, config)...)
+	}
+	return anomalies, nil
+}
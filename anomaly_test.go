@@ -0,0 +1,62 @@
+package npapi
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// anomalyHistory creates an hourly history from the hashrate function with 1% noise.
+func anomalyHistory(n int, hashrate func(i int) float64) []HistoryItem {
+	rng := rand.New(rand.NewSource(1))
+	start := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]HistoryItem, n)
+	for i := range history {
+		history[i] = HistoryItem{Date: Time(start.Add(time.Duration(i) * time.Hour)), Hashrate: hashrate(i) * (1 + 0.01*rng.NormFloat64())}
+	}
+	return history
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	tests := []struct {
+		name     string
+		hashrate func(i int) float64
+		want     []AnomalyKind
+	}{
+		{"drop", func(i int) float64 {
+			if i < 60 {
+				return 100
+			}
+			return 50
+		}, []AnomalyKind{SuddenDrop}},
+		{"degradation", func(i int) float64 {
+			if i < 48 {
+				return 100
+			}
+			return 100 - 0.5*float64(i-48)
+		}, []AnomalyKind{Degradation}},
+		{"flapping", func(i int) float64 {
+			if i >= 60 && i < 72 && i%2 == 0 {
+				return 0
+			}
+			return 100
+		}, []AnomalyKind{SuddenDrop, Flapping}},
+	}
+	for _, test := range tests {
+		found := make(map[AnomalyKind]bool)
+		for _, a := range DetectAnomalies("rig1", anomalyHistory(120, test.hashrate), DefaultAnomalyConfig) {
+			found[a.Kind] = true
+			if a.Kind == SuddenDrop && test.name == "drop" && (a.Severity < 0.45 || a.Severity > 0.55) {
+				t.Errorf("%s: expected a severity of about 0.5, got %f", test.name, a.Severity)
+			}
+		}
+		if len(found) != len(test.want) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, found)
+		}
+		for _, kind := range test.want {
+			if !found[kind] {
+				t.Errorf("%s: expected a %s", test.name, kind)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package npapi
+
+import (
+	"strings"
+	"time"
+)
+
+// BlockQuery selects blocks by miner address, date and difficulty. Zero values leave a criterion unrestricted.
+type BlockQuery struct {
+	// Miner addresses, compared case-insensitively
+	Miners []string
+	// Date range [From, To]
+	From, To time.Time
+	// Difficulty range [MinDifficulty, MaxDifficulty]
+	MinDifficulty, MaxDifficulty uint64
+}
+
+// hasMiner checks whether the address is contained in the list of addresses.
+func hasMiner(addrs []string, addr string) bool {
+	for _, a := range addrs {
+		if strings.EqualFold(a, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match checks whether the block satisfies the query.
+func (q BlockQuery) Match(b BlockItem) bool {
+	if len(q.Miners) > 0 && !hasMiner(q.Miners, b.Miner) {
+		return false
+	}
+	date := time.Time(b.Date)
+	if !q.From.IsZero() && date.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && date.After(q.To) {
+		return false
+	}
+	if b.Difficulty < q.MinDifficulty {
+		return false
+	}
+	if q.MaxDifficulty > 0 && b.Difficulty > q.MaxDifficulty {
+		return false
+	}
+	return true
+}
+
+// FilterBlocks returns the blocks satisfying the query.
+func FilterBlocks(blocks []BlockItem, q BlockQuery) []BlockItem {
+	var matches []BlockItem
+	for _, b := range blocks {
+		if q.Match(b) {
+			matches = append(matches, b)
+		}
+	}
+	return matches
+}
+
+// FindBlocks walks the blocks found by the pool back to the start of the query range and returns up to
+// limit matching blocks, newest first. A limit of zero returns all matches.
+func FindBlocks(q BlockQuery, limit int) ([]BlockItem, error) {
+	it := NewBlockIterator()
+	it.After = q.From
+	var matches []BlockItem
+	for it.Next() {
+		if !q.Match(it.Block()) {
+			continue
+		}
+		matches = append(matches, it.Block())
+		if limit > 0 && len(matches) >= limit {
+			break
+		}
+	}
+	return matches, it.Err()
+}
+
+// BlockWatcher reports newly fetched blocks mined by a set of watched addresses.
+type BlockWatcher struct {
+	// Watched miner addresses
+	Addresses []string
+	// OnFound is called for each new block mined by a watched address
+	OnFound func(BlockItem)
+
+	fetch func(offset, count uint) ([]BlockItem, error)
+	last  uint
+}
+
+// NewBlockWatcher creates a watcher for blocks mined by the given addresses.
+func NewBlockWatcher(addrs ...string) *BlockWatcher {
+	return &BlockWatcher{Addresses: addrs, fetch: Blocks}
+}
+
+// Poll fetches the blocks found since the previous poll and returns those mined by a watched address, oldest first.
+// The first poll only records the latest block, so that blocks found before the watcher was started are not reported.
+func (w *BlockWatcher) Poll() ([]BlockItem, error) {
+	if w.last == 0 {
+		blocks, err := w.fetch(0, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) > 0 {
+			w.last = blocks[0].Number
+		}
+		return nil, nil
+	}
+	it := &BlockIterator{PageSize: DefaultPageSize, MinNumber: w.last + 1, Forward: true, fetch: w.fetch}
+	var found []BlockItem
+	for it.Next() {
+		b := it.Block()
+		w.last = b.Number
+		if !hasMiner(w.Addresses, b.Miner) {
+			continue
+		}
+		found = append(found, b)
+		if w.OnFound != nil {
+			w.OnFound(b)
+		}
+	}
+	return found, it.Err()
+}
@@ -0,0 +1,241 @@
+package npapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 style currency code.
+type Currency string
+
+// Currencies with rates provided by PriceReport.
+const (
+	ETH Currency = "ETH"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	RUB Currency = "RUB"
+	CNY Currency = "CNY"
+	BTC Currency = "BTC"
+)
+
+// PriceCurrencies lists the currencies with rates provided by PriceReport.
+var PriceCurrencies = []Currency{USD, EUR, RUB, CNY, BTC}
+
+// ParseCurrency parses a three-letter currency code. The legacy code RUR is accepted for RUB.
+func ParseCurrency(s string) (Currency, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if code == "RUR" {
+		return RUB, nil
+	}
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency %q", s)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid currency %q", s)
+		}
+	}
+	return Currency(code), nil
+}
+
+// In returns the ETH price in the given currency.
+func (p PriceReport) In(c Currency) (float64, error) {
+	switch c {
+	case ETH:
+		return 1, nil
+	case USD:
+		return p.USDollar, nil
+	case EUR:
+		return p.Euro, nil
+	case RUB:
+		return p.Rubles, nil
+	case CNY:
+		return p.Yuan, nil
+	case BTC:
+		return p.Bitcoins, nil
+	}
+	return 0, fmt.Errorf("unsupported currency %q", c)
+}
+
+// In returns the earnings in the given currency.
+func (e EarningsItem) In(c Currency) (float64, error) {
+	switch c {
+	case ETH:
+		return e.Coins, nil
+	case USD:
+		return e.Dollars, nil
+	case EUR:
+		return e.Euros, nil
+	case RUB:
+		return e.Rubles, nil
+	case CNY:
+		return e.Yuan, nil
+	case BTC:
+		return e.Bitcoins, nil
+	}
+	return 0, fmt.Errorf("unsupported currency %q", c)
+}
+
+// Converter converts amounts between ETH and other currencies.
+type Converter struct {
+	// units of each currency per ETH
+	rates map[Currency]float64
+}
+
+// NewConverter creates a converter using the exchange rates of the price report.
+func NewConverter(prices PriceReport) *Converter {
+	c := &Converter{rates: map[Currency]float64{ETH: 1}}
+	for _, currency := range PriceCurrencies {
+		rate, _ := prices.In(currency)
+		if rate > 0 {
+			c.rates[currency] = rate
+		}
+	}
+	return c
+}
+
+// SetRate sets the price of one ETH in the given currency.
+func (c *Converter) SetRate(currency Currency, perETH float64) {
+	c.rates[currency] = perETH
+}
+
+// SetRelativeRate sets the rate of a currency relative to a currency already known, e.g. GBP per USD.
+func (c *Converter) SetRelativeRate(currency, base Currency, perBase float64) error {
+	rate, err := c.Rate(base)
+	if err != nil {
+		return err
+	}
+	c.rates[currency] = rate * perBase
+	return nil
+}
+
+// Rate returns the price of one ETH in the given currency.
+func (c *Converter) Rate(currency Currency) (float64, error) {
+	rate, ok := c.rates[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no rate known for %s", currency)
+	}
+	return rate, nil
+}
+
+// Convert converts an amount between two currencies.
+func (c *Converter) Convert(amount float64, from, to Currency) (float64, error) {
+	fromRate, err := c.Rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := c.Rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return amount / fromRate * toRate, nil
+}
+
+// Currencies lists all currencies with a known rate.
+func (c *Converter) Currencies() []Currency {
+	currencies := make([]Currency, 0, len(c.rates))
+	for currency := range c.rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+	return currencies
+}
+
+// Format describes how amounts of a currency are written.
+type Format struct {
+	// Currency symbol
+	Symbol string
+	// Suffix places the symbol after the amount, separated by a space
+	Suffix bool
+	// Number of decimals
+	Decimals int
+	// Decimal and thousands separators
+	Decimal, Thousands string
+}
+
+// Formats maps currencies to their conventional format. Currencies without an entry are written
+// with two decimals followed by their code.
+var Formats = map[Currency]Format{
+	ETH: {Symbol: "Ξ", Decimals: 6, Decimal: ".", Thousands: ","},
+	USD: {Symbol: "$", Decimals: 2, Decimal: ".", Thousands: ","},
+	EUR: {Symbol: "€", Suffix: true, Decimals: 2, Decimal: ",", Thousands: "."},
+	RUB: {Symbol: "₽", Suffix: true, Decimals: 2, Decimal: ",", Thousands: " "},
+	CNY: {Symbol: "¥", Decimals: 2, Decimal: ".", Thousands: ","},
+	BTC: {Symbol: "₿", Decimals: 8, Decimal: ".", Thousands: ","},
+}
+
+// Format writes the amount using the currency's conventional format, regardless of the reader's locale.
+func (c Currency) Format(amount float64) string {
+	return c.format().Format(amount)
+}
+
+func (c Currency) format() Format {
+	format, ok := Formats[c]
+	if !ok {
+		format = Format{Symbol: string(c), Suffix: true, Decimals: 2, Decimal: ".", Thousands: ","}
+	}
+	return format
+}
+
+// Locale describes how a region writes amounts.
+type Locale struct {
+	// Decimal and thousands separators
+	Decimal, Thousands string
+	// Suffix places the currency symbol after the amount, separated by a space
+	Suffix bool
+}
+
+// Locales maps language tags to their conventions for writing amounts. Further locales may be added.
+var Locales = map[string]Locale{
+	"en-US": {Decimal: ".", Thousands: ","},
+	"en-GB": {Decimal: ".", Thousands: ","},
+	"de-DE": {Decimal: ",", Thousands: ".", Suffix: true},
+	"fr-FR": {Decimal: ",", Thousands: "\u202f", Suffix: true},
+	"es-ES": {Decimal: ",", Thousands: ".", Suffix: true},
+	"ru-RU": {Decimal: ",", Thousands: "\u00a0", Suffix: true},
+	"zh-CN": {Decimal: ".", Thousands: ","},
+	"ja-JP": {Decimal: ".", Thousands: ","},
+}
+
+// FormatLocale writes the amount with the currency's symbol and precision, using the separators and
+// symbol placement of the locale with the given tag, which must be listed in Locales.
+func (c Currency) FormatLocale(amount float64, tag string) (string, error) {
+	locale, ok := Locales[tag]
+	if !ok {
+		return "", fmt.Errorf("unknown locale %q", tag)
+	}
+	format := c.format()
+	format.Decimal, format.Thousands, format.Suffix = locale.Decimal, locale.Thousands, locale.Suffix
+	return format.Format(amount), nil
+}
+
+// Format writes the amount using the format.
+func (f Format) Format(amount float64) string {
+	s := strconv.FormatFloat(math.Abs(amount), 'f', f.Decimals, 64)
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(f.Thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+	number := grouped.String()
+	if fraction != "" {
+		number += f.Decimal + fraction
+	}
+	sign := ""
+	if amount < 0 && strings.Trim(s, "0.") != "" {
+		sign = "-"
+	}
+	if f.Suffix {
+		return sign + number + " " + f.Symbol
+	}
+	return sign + f.Symbol + number
+}
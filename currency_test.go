@@ -0,0 +1,95 @@
+package npapi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConverter(t *testing.T) {
+	converter := NewConverter(PriceReport{USDollar: 300, Euro: 250, Bitcoins: 0.1})
+	if err := converter.SetRelativeRate("GBP", USD, 0.8); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		amount   float64
+		from, to Currency
+		want     float64
+	}{
+		{2, ETH, USD, 600},
+		{600, USD, EUR, 500},
+		{240, "GBP", ETH, 1},
+		{0.05, BTC, USD, 150},
+	}
+	for _, test := range tests {
+		got, err := converter.Convert(test.amount, test.from, test.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%f %s in %s: expected %f, got %f", test.amount, test.from, test.to, test.want, got)
+		}
+	}
+	if _, err := converter.Convert(1, RUB, USD); err == nil {
+		t.Error("expected an error converting a currency without rate")
+	}
+}
+
+func TestCurrencyFormat(t *testing.T) {
+	tests := []struct {
+		currency Currency
+		amount   float64
+		want     string
+	}{
+		{USD, 1234567.891, "$1,234,567.89"},
+		{EUR, -1234.5, "-1.234,50 €"},
+		{BTC, 0.000123, "₿0.00012300"},
+		{"GBP", 12, "12.00 GBP"},
+	}
+	for _, test := range tests {
+		if got := test.currency.Format(test.amount); got != test.want {
+			t.Errorf("expected %q, got %q", test.want, got)
+		}
+	}
+}
+
+func TestCurrencyFormatLocale(t *testing.T) {
+	tests := []struct {
+		currency Currency
+		locale   string
+		amount   float64
+		want     string
+	}{
+		{EUR, "en-US", 1234.5, "€1,234.50"},
+		{USD, "de-DE", 1234.5, "1.234,50 $"},
+		{CNY, "zh-CN", 1234.5, "¥1,234.50"},
+		{RUB, "ru-RU", 1234.5, "1\u00a0234,50 ₽"},
+	}
+	for _, test := range tests {
+		got, err := test.currency.FormatLocale(test.amount, test.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("expected %q, got %q", test.want, got)
+		}
+	}
+	if _, err := EUR.FormatLocale(1234.5, "it-IT"); err == nil {
+		t.Error("expected an error for an unknown locale")
+	}
+	if got, _ := RUB.FormatLocale(1234.5, "ru-RU"); got != RUB.Format(1234.5) {
+		t.Errorf("expected the ru-RU locale to match the conventional ruble format, got %q and %q", got, RUB.Format(1234.5))
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	for _, s := range []string{"usd", " EUR ", "rur", "GBP"} {
+		if _, err := ParseCurrency(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "EU", "FOOBAR", "12 ", "US$"} {
+		if c, err := ParseCurrency(s); err == nil {
+			t.Errorf("%q: expected an error, got %s", s, c)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package npapi
+
+import "sort"
+
+// DefaultDiscrepancyThreshold is the effective/reported ratio below which a worker is considered short.
+const DefaultDiscrepancyThreshold = 0.9
+
+// DiscrepancyItem compares the hashrate reported by a worker with the hashrate credited by the pool.
+type DiscrepancyItem struct {
+	// Worker ID
+	ID string
+	// Last reported hashrate [MH/s]
+	Reported float64
+	// Average effective hashrates [MH/s]
+	Effective HashrateReport
+	// Effective/reported ratios per window, zero if nothing was reported
+	Ratios HashrateReport
+	// Number of windows with a ratio below the threshold
+	ShortWindows int
+	// Persistent is true if the worker is short in every window
+	Persistent bool
+}
+
+// AnalyzeDiscrepancy computes the effective/reported ratio of each worker in every averaging window and
+// flags workers whose ratio stays below the threshold in all of them. Workers not reporting a hashrate are skipped.
+// A threshold <= 0 selects DefaultDiscrepancyThreshold.
+func AnalyzeDiscrepancy(reported []HashrateItem, averages WorkerHashrateReport, threshold float64) []DiscrepancyItem {
+	if threshold <= 0 {
+		threshold = DefaultDiscrepancyThreshold
+	}
+	effective := make(map[string]*HashrateReport)
+	windows := []struct {
+		items []HashrateItem
+		set   func(r *HashrateReport, v float64)
+	}{
+		{averages.LastHour, func(r *HashrateReport, v float64) { r.LastHour = v }},
+		{averages.LastThreeHours, func(r *HashrateReport, v float64) { r.LastThreeHours = v }},
+		{averages.LastSixHours, func(r *HashrateReport, v float64) { r.LastSixHours = v }},
+		{averages.LastTwelveHours, func(r *HashrateReport, v float64) { r.LastTwelveHours = v }},
+		{averages.LastDay, func(r *HashrateReport, v float64) { r.LastDay = v }},
+	}
+	for _, w := range windows {
+		for _, item := range w.items {
+			r, ok := effective[item.ID]
+			if !ok {
+				r = &HashrateReport{}
+				effective[item.ID] = r
+			}
+			w.set(r, item.Hashrate)
+		}
+	}
+	var items []DiscrepancyItem
+	for _, r := range reported {
+		if r.Hashrate <= 0 {
+			continue
+		}
+		item := DiscrepancyItem{ID: r.ID, Reported: r.Hashrate}
+		if e, ok := effective[r.ID]; ok {
+			item.Effective = *e
+		}
+		ratios := []*float64{
+			&item.Ratios.LastHour,
+			&item.Ratios.LastThreeHours,
+			&item.Ratios.LastSixHours,
+			&item.Ratios.LastTwelveHours,
+			&item.Ratios.LastDay,
+		}
+		values := []float64{
+			item.Effective.LastHour,
+			item.Effective.LastThreeHours,
+			item.Effective.LastSixHours,
+			item.Effective.LastTwelveHours,
+			item.Effective.LastDay,
+		}
+		for i, v := range values {
+			*ratios[i] = v / r.Hashrate
+			if *ratios[i] < threshold {
+				item.ShortWindows++
+			}
+		}
+		item.Persistent = item.ShortWindows == len(values)
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Ratios.LastDay < items[j].Ratios.LastDay })
+	return items
+}
+
+// HashrateDiscrepancy fetches the reported and average hashrates of all workers and analyzes their discrepancy.
+func HashrateDiscrepancy(addr string, threshold float64) ([]DiscrepancyItem, error) {
+	reported, err := WorkersReportedHashrate(addr)
+	if err != nil {
+		return nil, err
+	}
+	averages, err := WorkersAverageHashrate(addr)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeDiscrepancy(reported, averages, threshold), nil
+}
@@ -0,0 +1,38 @@
+package npapi
+
+import "testing"
+
+func TestAnalyzeDiscrepancy(t *testing.T) {
+	reported := []HashrateItem{{ID: "rig1", Hashrate: 100}, {ID: "rig2", Hashrate: 100}, {ID: "rig3", Hashrate: 0}}
+	window := []HashrateItem{{ID: "rig1", Hashrate: 80}, {ID: "rig2", Hashrate: 95}, {ID: "rig3", Hashrate: 50}}
+	averages := WorkerHashrateReport{
+		LastHour:        window,
+		LastThreeHours:  window,
+		LastSixHours:    window,
+		LastTwelveHours: window,
+		LastDay:         []HashrateItem{{ID: "rig1", Hashrate: 85}, {ID: "rig2", Hashrate: 95}},
+	}
+	tests := []struct {
+		name       string
+		threshold  float64
+		persistent map[string]bool
+	}{
+		{"default", 0, map[string]bool{"rig1": true, "rig2": false}},
+		{"negative", -1, map[string]bool{"rig1": true, "rig2": false}},
+		{"strict", 0.99, map[string]bool{"rig1": true, "rig2": true}},
+	}
+	for _, test := range tests {
+		items := AnalyzeDiscrepancy(reported, averages, test.threshold)
+		if len(items) != len(test.persistent) {
+			t.Fatalf("%s: expected %d workers, got %+v", test.name, len(test.persistent), items)
+		}
+		if items[0].ID != "rig1" || items[0].Ratios.LastDay != 0.85 {
+			t.Errorf("%s: expected rig1 first with a daily ratio of 0.85, got %+v", test.name, items[0])
+		}
+		for _, item := range items {
+			if item.Persistent != test.persistent[item.ID] {
+				t.Errorf("%s: %s: expected persistent %t, got %+v", test.name, item.ID, test.persistent[item.ID], item)
+			}
+		}
+	}
+}
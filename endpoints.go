@@ -5,6 +5,7 @@ package npapi
 
 const (
 	apiAddress                            = "https://api.nanopool.org/v1/eth"
+	coinAPIAddress                        = "https://api.nanopool.org/v1/%s"
 	accountBalanceEndpoint                = "%s/balance/%s"
 	averageHashrateLimitedEndpoint        = "%s/avghashratelimited/%s/%d"
 	averageHashrateEndpoint               = "%s/avghashrate/%s"
@@ -0,0 +1,158 @@
+package npapi
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// EnergyConfig describes the power draw of workers and the carbon intensity of their electricity.
+type EnergyConfig struct {
+	// Power draw per worker [W]
+	PowerWatts map[string]float64
+	// Default grid carbon intensity [gCO2/kWh]
+	GridIntensity float64
+	// Grid carbon intensity overrides per worker [gCO2/kWh]
+	WorkerIntensity map[string]float64
+	// Longest part of the period the uptime of a worker may be unknown for
+	MaxUnknown time.Duration
+}
+
+// intensity returns the grid carbon intensity of the worker.
+func (c EnergyConfig) intensity(worker string) float64 {
+	if i, ok := c.WorkerIntensity[worker]; ok {
+		return i
+	}
+	return c.GridIntensity
+}
+
+// WorkerEnergy stores the energy consumption of a single worker.
+type WorkerEnergy struct {
+	// Worker ID
+	Worker string
+	// Time spent online
+	Online time.Duration
+	// Consumed energy [kWh]
+	Energy float64
+	// Emitted CO2 [kg]
+	CO2 float64
+	// Coins attributed to the worker in ETH
+	Coins float64
+}
+
+// EnergyReport stores the energy consumption of a wallet in a period.
+type EnergyReport struct {
+	// Account address
+	Address string
+	// Reporting period
+	From, To time.Time
+	// Per-worker consumption, ordered by worker ID
+	Workers []WorkerEnergy
+	// Total consumed energy [kWh], emitted CO2 [kg] and mined coins in ETH
+	Energy, CO2, Coins float64
+}
+
+// EnergyPerCoin returns the energy consumed per mined coin [kWh/ETH].
+func (r EnergyReport) EnergyPerCoin() float64 {
+	if r.Coins <= 0 {
+		return 0
+	}
+	return r.Energy / r.Coins
+}
+
+// CO2PerCoin returns the CO2 emitted per mined coin [kg/ETH].
+func (r EnergyReport) CO2PerCoin() float64 {
+	if r.Coins <= 0 {
+		return 0
+	}
+	return r.CO2 / r.Coins
+}
+
+// NewEnergyReport computes the energy consumption of the workers from their uptime in the period and attributes
+// the confirmed payments made in the period to the workers by their shares. Workers without a configured power
+// draw consume no energy. Periods in which the uptime of a worker is unknown for longer than allowed by the
+// configuration are rejected, since their consumption would be underestimated.
+func NewEnergyReport(addr string, uptimes []UptimeReport, payments []Payment, shares map[string]float64,
+	config EnergyConfig, from, to time.Time) (EnergyReport, error) {
+	report := EnergyReport{Address: addr, From: from, To: to}
+	for _, p := range payments {
+		date := time.Time(p.Date)
+		if p.Confirmed && !date.Before(from) && date.Before(to) {
+			report.Coins += p.Amount
+		}
+	}
+	for _, u := range uptimes {
+		if u.Unknown > config.MaxUnknown {
+			return EnergyReport{}, fmt.Errorf("uptime of worker %s is unknown for %s of the period", u.Worker, u.Unknown)
+		}
+		energy := config.PowerWatts[u.Worker] * u.Online.Hours() / 1000
+		w := WorkerEnergy{
+			Worker: u.Worker,
+			Online: u.Online,
+			Energy: energy,
+			CO2:    energy * config.intensity(u.Worker) / 1000,
+			Coins:  report.Coins * shares[u.Worker],
+		}
+		report.Workers = append(report.Workers, w)
+		report.Energy += w.Energy
+		report.CO2 += w.CO2
+	}
+	sort.Slice(report.Workers, func(i, j int) bool { return report.Workers[i].Worker < report.Workers[j].Worker })
+	return report, nil
+}
+
+// WalletEnergy derives the worker uptimes in the period [from, to) from the share histories in the store,
+// fetches the payments of the account and computes its energy consumption. Payments are attributed to the
+// workers by their stored share counts in the period, including workers no longer listed by the pool. The store
+// must have been backfilled regularly during the period, see Store.WorkersUptime.
+func WalletEnergy(store *Store, addr string, config EnergyConfig, from, to time.Time) (EnergyReport, error) {
+	uptimes, err := store.WorkersUptime(addr, from, to)
+	if err != nil {
+		return EnergyReport{}, err
+	}
+	payments, err := Payments(addr)
+	if err != nil {
+		return EnergyReport{}, err
+	}
+	return store.energyReport(addr, uptimes, payments, config, from, to)
+}
+
+// energyReport attributes the payments to the workers of the uptime reports by their stored share counts
+// in the period and computes the energy report.
+func (s *Store) energyReport(addr string, uptimes []UptimeReport, payments []Payment, config EnergyConfig,
+	from, to time.Time) (EnergyReport, error) {
+	sums := make(map[string]float64, len(uptimes))
+	var total float64
+	for _, u := range uptimes {
+		points, err := s.Range(WorkerSharesSeries(addr, u.Worker), from, to.Add(-time.Nanosecond))
+		if err != nil {
+			return EnergyReport{}, err
+		}
+		for _, p := range points {
+			sums[u.Worker] += p.Value
+			total += p.Value
+		}
+	}
+	return NewEnergyReport(addr, uptimes, payments, normalizeShares(sums, total), config, from, to)
+}
+
+// WriteEnergyReports renders the reports as a table.
+func WriteEnergyReports(w io.Writer, reports []EnergyReport) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "WALLET\tWORKER\tONLINE\tENERGY [kWh]\tCO2 [kg]\tCOINS [ETH]\tkWh/ETH")
+	for _, r := range reports {
+		for _, e := range r.Workers {
+			perCoin := 0.0
+			if e.Coins > 0 {
+				perCoin = e.Energy / e.Coins
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%.2f\t%.2f\t%.6f\t%.2f\n",
+				r.Address, e.Worker, e.Online, e.Energy, e.CO2, e.Coins, perCoin)
+		}
+		fmt.Fprintf(table, "%s\t%s\t\t%.2f\t%.2f\t%.6f\t%.2f\n",
+			r.Address, "TOTAL", r.Energy, r.CO2, r.Coins, r.EnergyPerCoin())
+	}
+	return table.Flush()
+}
@@ -0,0 +1,91 @@
+package npapi
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewEnergyReport(t *testing.T) {
+	from := time.Date(2017, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 3, 0)
+	uptimes := []UptimeReport{
+		{Worker: "rig1", From: from, To: to, Covered: to.Sub(from), Online: 100 * time.Hour},
+		{Worker: "rig2", From: from, To: to, Covered: to.Sub(from), Online: 50 * time.Hour},
+	}
+	payments := []Payment{
+		{Date: Time(from.AddDate(0, 1, 0)), Amount: 1, Confirmed: true},
+		{Date: Time(from.AddDate(0, 2, 0)), Amount: 1, Confirmed: false},
+		{Date: Time(to), Amount: 1, Confirmed: true},
+	}
+	shares := map[string]float64{"rig1": 0.5, "rig2": 0.25, "rig3": 0.25}
+	config := EnergyConfig{PowerWatts: map[string]float64{"rig1": 1000, "rig2": 2000}, GridIntensity: 400}
+	report, err := NewEnergyReport("0x0", uptimes, payments, shares, config, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// coins of rig3, which has no uptime, still count towards the total
+	if report.Coins != 1 || report.Energy != 200 || math.Abs(report.CO2-80) > 1e-9 || report.EnergyPerCoin() != 200 {
+		t.Errorf("unexpected report with %f ETH, %f kWh and %f kg CO2", report.Coins, report.Energy, report.CO2)
+	}
+	if len(report.Workers) != 2 || report.Workers[1].Coins != 0.25 {
+		t.Errorf("unexpected workers %+v", report.Workers)
+	}
+	uptimes[1].Covered, uptimes[1].Unknown = 24*time.Hour, to.Sub(from)-24*time.Hour
+	if _, err := NewEnergyReport("0x0", uptimes, payments, shares, config, from, to); err == nil {
+		t.Error("expected an error for a period with unknown uptime")
+	}
+}
+
+func TestStoreEnergyReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "npapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(72 * time.Hour)
+	// rig1 is still listed, but the store was not polled for two days; rig2 was retired after the first day
+	var rig1, rig2 []Point
+	for bucket := from; bucket.Before(to); bucket = bucket.Add(ShareInterval) {
+		offset := bucket.Sub(from)
+		if offset < 12*time.Hour || offset >= 60*time.Hour {
+			rig1 = append(rig1, Point{Date: bucket, Value: 5})
+		}
+		if offset < 24*time.Hour {
+			rig2 = append(rig2, Point{Date: bucket, Value: 5})
+		}
+	}
+	if err := store.Append(WorkerSharesSeries("0x0", "rig1"), rig1...); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(WorkerSharesSeries("0x0", "rig2"), rig2...); err != nil {
+		t.Fatal(err)
+	}
+	uptimes, err := store.storedUptime("0x0", map[string]time.Time{"rig1": to.Add(-time.Minute)}, to, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payments := []Payment{{Date: Time(from.Add(36 * time.Hour)), Amount: 1, Confirmed: true}}
+	config := EnergyConfig{PowerWatts: map[string]float64{"rig1": 1000, "rig2": 1000}, MaxUnknown: 24 * time.Hour}
+	if _, err := store.energyReport("0x0", uptimes, payments, config, from, to); err == nil {
+		t.Error("expected an error for the polling gap")
+	}
+	config.MaxUnknown = 48 * time.Hour
+	report, err := store.energyReport("0x0", uptimes, payments, config, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Workers) != 2 || report.Workers[0].Coins != 0.5 || report.Workers[1].Coins != 0.5 {
+		t.Fatalf("expected the coins split between both rigs, got %+v", report.Workers)
+	}
+	if report.Workers[0].Energy != 24 || report.Workers[1].Energy != 24 {
+		t.Errorf("expected 24 kWh per rig, got %+v", report.Workers)
+	}
+}
@@ -0,0 +1,126 @@
+package npapi
+
+import (
+	"math/big"
+	"time"
+)
+
+// Ethash parameters as defined in the Ethereum yellow paper.
+const (
+	EpochLength        = 30000
+	datasetBytesInit   = 1 << 30
+	datasetBytesGrowth = 1 << 23
+	cacheBytesInit     = 1 << 24
+	cacheBytesGrowth   = 1 << 17
+	mixBytes           = 128
+	hashBytes          = 64
+)
+
+// Epoch returns the Ethash epoch of the given block.
+func Epoch(block uint64) uint64 {
+	return block / EpochLength
+}
+
+// isPrime checks whether n is prime.
+func isPrime(n uint64) bool {
+	return new(big.Int).SetUint64(n).ProbablyPrime(20)
+}
+
+// DatasetSize returns the exact DAG size in bytes of the given epoch.
+func DatasetSize(epoch uint64) uint64 {
+	size := datasetBytesInit + datasetBytesGrowth*epoch - mixBytes
+	for !isPrime(size / mixBytes) {
+		size -= 2 * mixBytes
+	}
+	return size
+}
+
+// CacheSize returns the exact verification cache size in bytes of the given epoch.
+func CacheSize(epoch uint64) uint64 {
+	size := cacheBytesInit + cacheBytesGrowth*epoch - hashBytes
+	for !isPrime(size / hashBytes) {
+		size -= 2 * hashBytes
+	}
+	return size
+}
+
+// ExceedingEpoch returns the first epoch whose DAG no longer fits into the given memory size in bytes.
+func ExceedingEpoch(memory uint64) uint64 {
+	if memory < datasetBytesInit {
+		return 0
+	}
+	// DAG sizes stay within two mix lengths below the linear bound, so start searching just before it.
+	epoch := (memory - datasetBytesInit) / datasetBytesGrowth
+	for DatasetSize(epoch) <= memory {
+		epoch++
+	}
+	return epoch
+}
+
+// EpochInfo stores the DAG properties of the current epoch.
+type EpochInfo struct {
+	// Latest block number
+	Block uint64
+	// Current epoch
+	Epoch uint64
+	// DAG and cache size in bytes
+	DatasetSize, CacheSize uint64
+	// Start of the next epoch
+	NextEpoch time.Time
+}
+
+// ExhaustionInfo stores when the DAG will exceed a given memory size.
+type ExhaustionInfo struct {
+	// Memory size in bytes
+	Memory uint64
+	// First epoch exceeding the memory size
+	Epoch uint64
+	// First block of the epoch
+	Block uint64
+	// Estimated date of the block
+	Date time.Time
+}
+
+// CurrentEpoch fetches the latest block number and time to the next epoch and computes the current DAG properties.
+func CurrentEpoch() (EpochInfo, error) {
+	block, err := LastBlockNumber()
+	if err != nil {
+		return EpochInfo{}, err
+	}
+	next, err := NextEpoch()
+	if err != nil {
+		return EpochInfo{}, err
+	}
+	epoch := Epoch(uint64(block))
+	return EpochInfo{
+		Block:       uint64(block),
+		Epoch:       epoch,
+		DatasetSize: DatasetSize(epoch),
+		CacheSize:   CacheSize(epoch),
+		NextEpoch:   next,
+	}, nil
+}
+
+// EstimateExhaustion computes when the DAG will exceed the given memory size, estimating the date from the given block
+// number and the average block time [s].
+func EstimateExhaustion(memory, block uint64, now time.Time, blocktime float64) ExhaustionInfo {
+	epoch := ExceedingEpoch(memory)
+	info := ExhaustionInfo{Memory: memory, Epoch: epoch, Block: epoch * EpochLength, Date: now}
+	if info.Block > block {
+		info.Date = now.Add(time.Duration(float64(info.Block-block) * blocktime * float64(time.Second)))
+	}
+	return info
+}
+
+// DAGExhaustion fetches the latest block number and average block time and estimates when the DAG will exceed the given memory size in bytes.
+func DAGExhaustion(memory uint64) (ExhaustionInfo, error) {
+	block, err := LastBlockNumber()
+	if err != nil {
+		return ExhaustionInfo{}, err
+	}
+	blocktime, err := AverageBlocktime()
+	if err != nil {
+		return ExhaustionInfo{}, err
+	}
+	return EstimateExhaustion(memory, uint64(block), time.Now(), blocktime), nil
+}
@@ -0,0 +1,30 @@
+package npapi
+
+import "testing"
+
+func TestEpochSizes(t *testing.T) {
+	tests := []struct {
+		epoch          uint64
+		dataset, cache uint64
+	}{
+		{0, 1073739904, 16776896},
+		{1, 1082130304, 16907456},
+		{2, 1090514816, 17039296},
+	}
+	for _, test := range tests {
+		if size := DatasetSize(test.epoch); size != test.dataset {
+			t.Errorf("epoch %d: expected dataset size %d, got %d", test.epoch, test.dataset, size)
+		}
+		if size := CacheSize(test.epoch); size != test.cache {
+			t.Errorf("epoch %d: expected cache size %d, got %d", test.epoch, test.cache, size)
+		}
+	}
+}
+
+func TestExceedingEpoch(t *testing.T) {
+	const memory = 4 << 30
+	epoch := ExceedingEpoch(memory)
+	if DatasetSize(epoch) <= memory || DatasetSize(epoch-1) > memory {
+		t.Errorf("epoch %d is not the first epoch exceeding 4GB", epoch)
+	}
+}
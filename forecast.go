@@ -0,0 +1,161 @@
+package npapi
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Holt smoothing factors used for hashrate forecasts.
+const (
+	forecastLevelSmoothing = 0.5
+	forecastTrendSmoothing = 0.3
+)
+
+// MinForecastDays is the number of daily hashrate averages needed to estimate the uncertainty of a forecast.
+const MinForecastDays = 3
+
+// Band is a forecast value with its uncertainty band.
+type Band struct {
+	Low, Expected, High float64
+}
+
+// ForecastDay stores the forecast of a single day.
+type ForecastDay struct {
+	// Day start
+	Date time.Time
+	// Average hashrate [MH/s]
+	Hashrate Band
+	// Mined coins in ETH
+	Coins Band
+	// Value of the mined coins
+	Value Band
+}
+
+// Forecast stores the projected hashrate and earnings of the next days.
+type Forecast struct {
+	// Currency of the values
+	Currency Currency
+	// Confidence level of the bands
+	Confidence float64
+	// Forecast days, ordered by date
+	Days []ForecastDay
+}
+
+// holt fits a linear trend using double exponential smoothing and returns the final level and trend
+// together with the standard deviation of the one-step-ahead residuals.
+func holt(values []float64, alpha, beta float64) (level, trend, sigma float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+	level = values[0]
+	if len(values) > 1 {
+		trend = values[1] - values[0]
+	}
+	var squares float64
+	for _, v := range values[1:] {
+		residual := v - (level + trend)
+		squares += residual * residual
+		previous := level
+		level = alpha*v + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+	}
+	if len(values) > 1 {
+		sigma = math.Sqrt(squares / float64(len(values)-1))
+	}
+	return level, trend, sigma
+}
+
+// band creates a band around the expected value, clamped to non-negative values.
+func band(expected, spread float64) Band {
+	return Band{
+		Low:      math.Max(0, expected-spread),
+		Expected: math.Max(0, expected),
+		High:     math.Max(0, expected+spread),
+	}
+}
+
+// checkConfidence checks that the confidence level lies in (0, 1), e.g. 0.9 rather than 90.
+func checkConfidence(confidence float64) error {
+	if !(confidence > 0 && confidence < 1) {
+		return fmt.Errorf("confidence %g is not between 0 and 1", confidence)
+	}
+	return nil
+}
+
+// NewForecast projects the daily hashrate of the history for the next days using Holt's linear trend method
+// and derives coin earnings and their value. Earnings are scaled from the daily earnings of the given report
+// for the reference hashrate [MH/s] and adjusted to the difficulty projected by the network trend.
+// Bands widen with the square root of the horizon at the given confidence level, which must lie in (0, 1).
+// Histories covering fewer than MinForecastDays days are rejected, as their bands would collapse.
+func NewForecast(history []HistoryItem, earnings EarningsReport, referenceHashrate float64, network NetworkTrend,
+	price float64, currency Currency, days int, confidence float64) (Forecast, error) {
+	if err := checkConfidence(confidence); err != nil {
+		return Forecast{}, err
+	}
+	forecast := Forecast{Currency: currency, Confidence: confidence}
+	daily := Resample(HistoryPoints(history), 24*time.Hour, Mean, time.UTC)
+	if len(daily) < MinForecastDays {
+		return Forecast{}, fmt.Errorf("hashrate history covers %d days, need at least %d", len(daily), MinForecastDays)
+	}
+	if referenceHashrate <= 0 {
+		return forecast, nil
+	}
+	values := make([]float64, len(daily))
+	for i, p := range daily {
+		values[i] = p.Value
+	}
+	level, trend, sigma := holt(values, forecastLevelSmoothing, forecastTrendSmoothing)
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	coinsPerHashrate := earnings.PerDay.Coins / referenceHashrate
+	last := daily[len(daily)-1].Date
+	for h := 1; h <= days; h++ {
+		date := last.AddDate(0, 0, h)
+		hashrate := band(level+float64(h)*trend, z*sigma*math.Sqrt(float64(h)))
+		factor := 1.0
+		if projected := network.ProjectDifficulty(date); network.Difficulty > 0 && projected > 0 {
+			factor = network.Difficulty / projected
+		}
+		coins := Band{
+			Low:      hashrate.Low * coinsPerHashrate * factor,
+			Expected: hashrate.Expected * coinsPerHashrate * factor,
+			High:     hashrate.High * coinsPerHashrate * factor,
+		}
+		forecast.Days = append(forecast.Days, ForecastDay{
+			Date:     date,
+			Hashrate: hashrate,
+			Coins:    coins,
+			Value:    Band{Low: coins.Low * price, Expected: coins.Expected * price, High: coins.High * price},
+		})
+	}
+	return forecast, nil
+}
+
+// ForecastEarnings fetches the hashrate history of the account, the network trend of the last TrendSpan,
+// approximated earnings and prices and forecasts the next days in the given currency.
+func ForecastEarnings(addr string, days int, currency Currency, confidence float64) (Forecast, error) {
+	if err := checkConfidence(confidence); err != nil {
+		return Forecast{}, err
+	}
+	history, err := HashrateHistory(addr)
+	if err != nil {
+		return Forecast{}, err
+	}
+	network, err := FetchNetworkTrend(TrendSpan, TrendWindow)
+	if err != nil {
+		return Forecast{}, err
+	}
+	earnings, err := ApproximatedEarnings(1)
+	if err != nil {
+		return Forecast{}, err
+	}
+	prices, err := Prices()
+	if err != nil {
+		return Forecast{}, err
+	}
+	price, err := prices.In(currency)
+	if err != nil {
+		return Forecast{}, err
+	}
+	return NewForecast(history, earnings, 1, network, price, currency, days, confidence)
+}
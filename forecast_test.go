@@ -0,0 +1,53 @@
+package npapi
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// hourlyHistory creates an hourly hashrate history of the given days around 100 MH/s with 10% noise.
+func hourlyHistory(rng *rand.Rand, days int) []HistoryItem {
+	start := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	var history []HistoryItem
+	for date := start; date.Before(start.AddDate(0, 0, days)); date = date.Add(time.Hour) {
+		history = append(history, HistoryItem{Date: Time(date), Hashrate: 100 * (1 + 0.1*rng.NormFloat64())})
+	}
+	return history
+}
+
+func TestNewForecast(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	earnings := EarningsReport{PerDay: EarningsItem{Coins: 0.01}}
+	for days := 0; days < MinForecastDays; days++ {
+		if _, err := NewForecast(hourlyHistory(rng, days), earnings, 1, NetworkTrend{}, 300, USD, 7, 0.9); err == nil {
+			t.Errorf("expected an error forecasting from %d days", days)
+		}
+	}
+	history := hourlyHistory(rng, 14)
+	if _, err := NewForecast(history, earnings, 1, NetworkTrend{}, 300, USD, 7, 90); err == nil {
+		t.Error("expected an error for a confidence level of 90")
+	}
+	forecast, err := NewForecast(history, earnings, 1, NetworkTrend{}, 300, USD, 7, 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forecast.Days) != 7 {
+		t.Fatalf("expected 7 days, got %d", len(forecast.Days))
+	}
+	var width float64
+	for i, d := range forecast.Days {
+		if math.Abs(d.Hashrate.Expected-100) > 10 {
+			t.Errorf("day %d: expected about 100 MH/s, got %f", i, d.Hashrate.Expected)
+		}
+		w := d.Hashrate.High - d.Hashrate.Low
+		if w <= width {
+			t.Errorf("day %d: expected the band to widen, got %+v", i, d.Hashrate)
+		}
+		width = w
+		if math.Abs(d.Value.Expected-d.Hashrate.Expected*0.01*300) > 1e-9 {
+			t.Errorf("day %d: expected the value of the projected coins, got %+v", i, d.Value)
+		}
+	}
+}
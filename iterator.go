@@ -0,0 +1,280 @@
+package npapi
+
+import "time"
+
+// DefaultPageSize is the number of items iterators fetch per request.
+const DefaultPageSize = 50
+
+// BlockIterator walks the blocks found by the pool, starting with the latest one.
+//
+//	it := NewBlockIterator()
+//	it.After = time.Now().AddDate(0, -1, 0)
+//	for it.Next() {
+//		block := it.Block()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type BlockIterator struct {
+	// Number of blocks fetched per request
+	PageSize uint
+	// Stop at blocks with a lower number
+	MinNumber uint
+	// Stop at blocks found before this date
+	After time.Time
+	// Forward yields the blocks in ascending order once all blocks within the bounds are fetched
+	Forward bool
+
+	fetch   func(offset, count uint) ([]BlockItem, error)
+	offset  uint
+	page    []BlockItem
+	last    uint
+	started bool
+	done    bool
+	current BlockItem
+	err     error
+}
+
+// NewBlockIterator creates an iterator over the blocks found by the pool.
+func NewBlockIterator() *BlockIterator {
+	return &BlockIterator{PageSize: DefaultPageSize, fetch: Blocks}
+}
+
+// inBounds checks whether the block lies within the iterator bounds.
+func (it *BlockIterator) inBounds(b BlockItem) bool {
+	return b.Number >= it.MinNumber && !time.Time(b.Date).Before(it.After)
+}
+
+// nextBackward returns the next older block, skipping blocks already seen in previous pages.
+func (it *BlockIterator) nextBackward() (BlockItem, bool) {
+	for {
+		for len(it.page) > 0 {
+			b := it.page[0]
+			it.page = it.page[1:]
+			if it.started && b.Number >= it.last {
+				continue
+			}
+			if !it.inBounds(b) {
+				return BlockItem{}, false
+			}
+			it.started, it.last = true, b.Number
+			return b, true
+		}
+		pageSize := it.PageSize
+		if pageSize == 0 {
+			pageSize = DefaultPageSize
+		}
+		page, err := it.fetch(it.offset, pageSize)
+		if err != nil {
+			it.err = err
+			return BlockItem{}, false
+		}
+		if len(page) == 0 {
+			return BlockItem{}, false
+		}
+		it.offset += uint(len(page))
+		it.page = sortedBlocks(page)
+		for i, j := 0, len(it.page)-1; i < j; i, j = i+1, j-1 {
+			it.page[i], it.page[j] = it.page[j], it.page[i]
+		}
+	}
+}
+
+// Next advances the iterator to the next block and reports whether there is one.
+func (it *BlockIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if it.Forward {
+		if !it.started {
+			var blocks []BlockItem
+			for b, ok := it.nextBackward(); ok; b, ok = it.nextBackward() {
+				blocks = append(blocks, b)
+			}
+			it.started = true
+			if it.err != nil {
+				it.done = true
+				return false
+			}
+			it.page = sortedBlocks(blocks)
+		}
+		if len(it.page) == 0 {
+			it.done = true
+			return false
+		}
+		it.current, it.page = it.page[0], it.page[1:]
+		return true
+	}
+	b, ok := it.nextBackward()
+	if !ok {
+		it.done = true
+		return false
+	}
+	it.current = b
+	return true
+}
+
+// Block returns the current block.
+func (it *BlockIterator) Block() BlockItem {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *BlockIterator) Err() error {
+	return it.err
+}
+
+// NumberedBlockStat is a block stat associated with its block number.
+type NumberedBlockStat struct {
+	// Block number
+	Number uint
+	BlockStatItem
+}
+
+// BlockStatIterator walks the network block stats, starting with the latest block.
+type BlockStatIterator struct {
+	// Number of stats fetched per request
+	PageSize uint
+	// Stop at blocks with a lower number
+	MinNumber uint
+	// Stop at blocks created before this date
+	After time.Time
+	// Forward yields the stats in ascending order once all stats within the bounds are fetched
+	Forward bool
+
+	fetch      func(offset, count uint) ([]BlockStatItem, error)
+	lastNumber func() (uint, error)
+	offset     uint
+	page       []NumberedBlockStat
+	last       uint
+	started    bool
+	done       bool
+	current    NumberedBlockStat
+	err        error
+}
+
+// NewBlockStatIterator creates an iterator over the network block stats.
+func NewBlockStatIterator() *BlockStatIterator {
+	return &BlockStatIterator{PageSize: DefaultPageSize, fetch: BlockStats, lastNumber: LastBlockNumber}
+}
+
+// maxPageAttempts is the number of times a page of block stats is refetched while new blocks shift it.
+const maxPageAttempts = 3
+
+// nextBackward returns the stats of the next older block. Block numbers are derived from the
+// latest block number at the time each page is fetched, which also deduplicates pages shifted by new blocks.
+func (it *BlockStatIterator) nextBackward() (NumberedBlockStat, bool) {
+	for {
+		for len(it.page) > 0 {
+			s := it.page[0]
+			it.page = it.page[1:]
+			if it.started && s.Number >= it.last {
+				continue
+			}
+			if s.Number < it.MinNumber || time.Time(s.Date).Before(it.After) {
+				return NumberedBlockStat{}, false
+			}
+			it.started, it.last = true, s.Number
+			return s, true
+		}
+		latest, stats, err := it.fetchPage()
+		if err != nil {
+			it.err = err
+			return NumberedBlockStat{}, false
+		}
+		if len(stats) == 0 {
+			return NumberedBlockStat{}, false
+		}
+		it.page = make([]NumberedBlockStat, len(stats))
+		for i, s := range stats {
+			it.page[i] = NumberedBlockStat{Number: latest - it.offset - uint(i), BlockStatItem: s}
+		}
+		it.offset += uint(len(stats))
+	}
+}
+
+// fetchPage fetches the next page of stats along with the latest block number it is relative to. A page is
+// refetched if a new block was created while fetching it, which would shift the page against the number.
+// If blocks keep arriving, the last attempt is used, so numbering is best-effort under heavy block rates.
+func (it *BlockStatIterator) fetchPage() (uint, []BlockStatItem, error) {
+	pageSize := it.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	latest, err := it.lastNumber()
+	if err != nil {
+		return 0, nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		if it.started {
+			if latest < it.last {
+				latest = it.last
+			}
+			// skip the blocks created since the previous page
+			it.offset = latest - it.last + 1
+		}
+		if it.offset > latest {
+			return latest, nil, nil
+		}
+		stats, err := it.fetch(it.offset, pageSize)
+		if err != nil {
+			return 0, nil, err
+		}
+		after, err := it.lastNumber()
+		if err != nil {
+			return 0, nil, err
+		}
+		if after == latest || attempt == maxPageAttempts {
+			return latest, stats, nil
+		}
+		latest = after
+	}
+}
+
+// Next advances the iterator to the next block stat and reports whether there is one.
+func (it *BlockStatIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if it.Forward {
+		if !it.started {
+			var stats []NumberedBlockStat
+			for s, ok := it.nextBackward(); ok; s, ok = it.nextBackward() {
+				stats = append(stats, s)
+			}
+			it.started = true
+			if it.err != nil {
+				it.done = true
+				return false
+			}
+			for i, j := 0, len(stats)-1; i < j; i, j = i+1, j-1 {
+				stats[i], stats[j] = stats[j], stats[i]
+			}
+			it.page = stats
+		}
+		if len(it.page) == 0 {
+			it.done = true
+			return false
+		}
+		it.current, it.page = it.page[0], it.page[1:]
+		return true
+	}
+	s, ok := it.nextBackward()
+	if !ok {
+		it.done = true
+		return false
+	}
+	it.current = s
+	return true
+}
+
+// Stat returns the current block stat.
+func (it *BlockStatIterator) Stat() NumberedBlockStat {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *BlockStatIterator) Err() error {
+	return it.err
+}
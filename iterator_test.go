@@ -0,0 +1,100 @@
+package npapi
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeChain serves blocks and block stats newest first, appending a new block before every fetch with a shift.
+type fakeChain struct {
+	head   uint
+	shifts []bool
+	calls  int
+}
+
+// arrive appends a new block if the current fetch is marked as shifted.
+func (c *fakeChain) arrive() {
+	if c.calls < len(c.shifts) && c.shifts[c.calls] {
+		c.head++
+	}
+	c.calls++
+}
+
+func (c *fakeChain) stats(offset, count uint) ([]BlockStatItem, error) {
+	c.arrive()
+	var stats []BlockStatItem
+	for i := uint(0); i < count && offset+i <= c.head; i++ {
+		n := c.head - offset - i
+		stats = append(stats, BlockStatItem{Date: Time(time.Unix(int64(n)*15, 0)), Difficulty: uint64(n)})
+	}
+	return stats, nil
+}
+
+func (c *fakeChain) blocks(offset, count uint) ([]BlockItem, error) {
+	c.arrive()
+	var blocks []BlockItem
+	for i := uint(0); i < count && offset+i <= c.head; i++ {
+		n := c.head - offset - i
+		blocks = append(blocks, BlockItem{Number: n, Date: Time(time.Unix(int64(n)*15, 0))})
+	}
+	return blocks, nil
+}
+
+func (c *fakeChain) lastNumber() (uint, error) {
+	return c.head, nil
+}
+
+func TestBlockStatIterator(t *testing.T) {
+	for _, forward := range []bool{false, true} {
+		chain := &fakeChain{head: 100, shifts: []bool{true, false, false, true, false, true, true, false}}
+		it := &BlockStatIterator{PageSize: 10, MinNumber: 50, Forward: forward, fetch: chain.stats, lastNumber: chain.lastNumber}
+		var numbers []uint
+		for it.Next() {
+			s := it.Stat()
+			if uint64(s.Number) != s.Difficulty {
+				t.Errorf("forward %t: stat of block %d labeled as block %d", forward, s.Difficulty, s.Number)
+			}
+			numbers = append(numbers, s.Number)
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		checkNumbers(t, "stats", forward, numbers, 50)
+	}
+}
+
+func TestBlockIterator(t *testing.T) {
+	for _, forward := range []bool{false, true} {
+		chain := &fakeChain{head: 100, shifts: []bool{false, true, false, true, true}}
+		it := &BlockIterator{PageSize: 10, After: time.Unix(50*15, 0), Forward: forward, fetch: chain.blocks}
+		var numbers []uint
+		for it.Next() {
+			numbers = append(numbers, it.Block().Number)
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		checkNumbers(t, "blocks", forward, numbers, 50)
+	}
+}
+
+// checkNumbers checks that the numbers run without duplicates or holes down to min, in the iteration order.
+func checkNumbers(t *testing.T, name string, forward bool, numbers []uint, min uint) {
+	t.Helper()
+	if len(numbers) == 0 {
+		t.Fatalf("%s: forward %t: no items", name, forward)
+	}
+	if forward {
+		for i, j := 0, len(numbers)-1; i < j; i, j = i+1, j-1 {
+			numbers[i], numbers[j] = numbers[j], numbers[i]
+		}
+	}
+	for i, n := range numbers {
+		if n != numbers[0]-uint(i) {
+			t.Fatalf("%s: forward %t: expected consecutive numbers, got %v", name, forward, numbers)
+		}
+	}
+	if numbers[0] < 100 || numbers[len(numbers)-1] != min {
+		t.Errorf("%s: forward %t: expected numbers from at least 100 down to %d, got %v", name, forward, min, numbers)
+	}
+}
@@ -0,0 +1,272 @@
+package npapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxSnapshotInterval is the longest interval between two balance snapshots that is not treated as a gap.
+const DefaultMaxSnapshotInterval = 2 * time.Hour
+
+// Snapshot stores the account balance at a specific point in time.
+type Snapshot struct {
+	// Snapshot date
+	Date time.Time
+	// Account balance
+	Balance float64
+}
+
+// Gap is an interval in which balance snapshots were missed.
+type Gap struct {
+	// Last snapshot before the gap
+	From time.Time
+	// First snapshot after the gap
+	To time.Time
+	// Earnings realized during the gap, which are not attributed to any day
+	Earnings float64
+}
+
+// DailyEarnings stores the realized earnings of a single day.
+type DailyEarnings struct {
+	// Start of the day
+	Day time.Time
+	// Realized earnings in ETH
+	Earnings float64
+	// Payouts made during the day
+	Payouts float64
+	// Time of the day backed by snapshots
+	Coverage time.Duration
+	// Complete is true if the whole day is backed by snapshots
+	Complete bool
+}
+
+// Ledger derives realized earnings from periodic balance snapshots and the payouts made in between.
+type Ledger struct {
+	// Account address
+	Address string
+	// Longest interval between two snapshots that is not considered a gap
+	MaxInterval time.Duration
+	// Balance snapshots, ordered by date
+	Snapshots []Snapshot
+	// Payments, ordered by date
+	Payments []Payment
+}
+
+// NewLedger creates an empty ledger for the given account.
+func NewLedger(addr string) *Ledger {
+	return &Ledger{
+		Address:     addr,
+		MaxInterval: DefaultMaxSnapshotInterval,
+	}
+}
+
+// LoadLedger reads a ledger previously stored using Save.
+func LoadLedger(path string) (*Ledger, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	ledger := NewLedger("")
+	if err := json.NewDecoder(file).Decode(ledger); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// Save stores the ledger in the given file.
+func (l *Ledger) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(l); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Record fetches the current balance and payments of the account and adds them to the ledger.
+func (l *Ledger) Record() error {
+	balance, err := Balance(l.Address)
+	if err != nil {
+		return err
+	}
+	payments, err := Payments(l.Address)
+	if err != nil {
+		return err
+	}
+	l.AddPayments(payments)
+	l.AddSnapshot(Snapshot{Date: time.Now(), Balance: balance})
+	return nil
+}
+
+// AddSnapshot inserts a balance snapshot into the ledger.
+func (l *Ledger) AddSnapshot(s Snapshot) {
+	i := sort.Search(len(l.Snapshots), func(i int) bool {
+		return !l.Snapshots[i].Date.Before(s.Date)
+	})
+	if i < len(l.Snapshots) && l.Snapshots[i].Date.Equal(s.Date) {
+		l.Snapshots[i] = s
+		return
+	}
+	l.Snapshots = append(l.Snapshots, Snapshot{})
+	copy(l.Snapshots[i+1:], l.Snapshots[i:])
+	l.Snapshots[i] = s
+}
+
+// AddPayments adds payments to the ledger, skipping those already known by their transaction hash.
+func (l *Ledger) AddPayments(payments []Payment) {
+	known := make(map[string]int, len(l.Payments))
+	for i, p := range l.Payments {
+		known[p.TxHash] = i
+	}
+	for _, p := range payments {
+		if i, ok := known[p.TxHash]; ok {
+			l.Payments[i] = p
+			continue
+		}
+		known[p.TxHash] = len(l.Payments)
+		l.Payments = append(l.Payments, p)
+	}
+	sort.SliceStable(l.Payments, func(i, j int) bool {
+		return time.Time(l.Payments[i].Date).Before(time.Time(l.Payments[j].Date))
+	})
+}
+
+// payoutsBetween sums the payments made in the interval (from, to].
+func (l *Ledger) payoutsBetween(from, to time.Time) float64 {
+	var sum float64
+	for _, p := range l.Payments {
+		date := time.Time(p.Date)
+		if date.After(from) && !date.After(to) {
+			sum += p.Amount
+		}
+	}
+	return sum
+}
+
+// earningsBetween computes the realized earnings between two consecutive snapshots.
+func (l *Ledger) earningsBetween(a, b Snapshot) float64 {
+	return b.Balance - a.Balance + l.payoutsBetween(a.Date, b.Date)
+}
+
+func (l *Ledger) maxInterval() time.Duration {
+	if l.MaxInterval <= 0 {
+		return DefaultMaxSnapshotInterval
+	}
+	return l.MaxInterval
+}
+
+// Gaps lists all intervals in which snapshots were missed.
+func (l *Ledger) Gaps() []Gap {
+	var gaps []Gap
+	for i := 1; i < len(l.Snapshots); i++ {
+		a, b := l.Snapshots[i-1], l.Snapshots[i]
+		if b.Date.Sub(a.Date) > l.maxInterval() {
+			gaps = append(gaps, Gap{From: a.Date, To: b.Date, Earnings: l.earningsBetween(a, b)})
+		}
+	}
+	return gaps
+}
+
+// startOfDay returns the beginning of the day t belongs to in the given location.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// nextDay returns the beginning of the day following the given day start.
+func nextDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+}
+
+// Daily derives the realized earnings per day, using day boundaries in the given location.
+// Earnings of intervals spanning midnight are split proportionally to time. Intervals
+// exceeding MaxInterval are reported by Gaps and never attributed to a day, leaving the
+// affected days incomplete.
+func (l *Ledger) Daily(loc *time.Location) []DailyEarnings {
+	if len(l.Snapshots) == 0 {
+		return nil
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	days := make(map[time.Time]*DailyEarnings)
+	var order []time.Time
+	dayOf := func(start time.Time) *DailyEarnings {
+		if d, ok := days[start]; ok {
+			return d
+		}
+		d := &DailyEarnings{Day: start}
+		days[start] = d
+		order = append(order, start)
+		return d
+	}
+	first, last := l.Snapshots[0].Date, l.Snapshots[len(l.Snapshots)-1].Date
+	for day := startOfDay(first, loc); !day.After(last); day = nextDay(day) {
+		dayOf(day)
+	}
+	for i := 1; i < len(l.Snapshots); i++ {
+		a, b := l.Snapshots[i-1], l.Snapshots[i]
+		span := b.Date.Sub(a.Date)
+		if span <= 0 || span > l.maxInterval() {
+			continue
+		}
+		earnings := l.earningsBetween(a, b)
+		for from := a.Date; from.Before(b.Date); {
+			day := startOfDay(from, loc)
+			to := nextDay(day)
+			if to.After(b.Date) {
+				to = b.Date
+			}
+			d := dayOf(day)
+			d.Coverage += to.Sub(from)
+			d.Earnings += earnings * float64(to.Sub(from)) / float64(span)
+			from = to
+		}
+	}
+	for _, p := range l.Payments {
+		date := time.Time(p.Date)
+		if date.Before(first) || date.After(last) {
+			continue
+		}
+		dayOf(startOfDay(date, loc)).Payouts += p.Amount
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i].Before(order[j]) })
+	daily := make([]DailyEarnings, len(order))
+	for i, start := range order {
+		d := days[start]
+		d.Complete = d.Coverage >= nextDay(start).Sub(start)
+		daily[i] = *d
+	}
+	return daily
+}
+
+// ExportCSV writes the daily earnings as CSV, using day boundaries in the given location.
+func (l *Ledger) ExportCSV(w io.Writer, loc *time.Location) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"day", "earnings", "payouts", "coverage_hours", "complete"}); err != nil {
+		return err
+	}
+	for _, d := range l.Daily(loc) {
+		record := []string{
+			d.Day.Format("2006-01-02"),
+			strconv.FormatFloat(d.Earnings, 'f', -1, 64),
+			strconv.FormatFloat(d.Payouts, 'f', -1, 64),
+			strconv.FormatFloat(d.Coverage.Hours(), 'f', 2, 64),
+			strconv.FormatBool(d.Complete),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,33 @@
+package npapi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLedgerDaily(t *testing.T) {
+	loc := time.UTC
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, loc)
+	ledger := NewLedger("0x0")
+	ledger.AddSnapshot(Snapshot{Date: day.Add(22 * time.Hour), Balance: 1.0})
+	ledger.AddSnapshot(Snapshot{Date: day.Add(26 * time.Hour), Balance: 1.4})
+	ledger.AddSnapshot(Snapshot{Date: day.Add(27 * time.Hour), Balance: 0.1})
+	ledger.AddSnapshot(Snapshot{Date: day.Add(28 * time.Hour), Balance: 0.4})
+	ledger.AddPayments([]Payment{{Date: Time(day.Add(26*time.Hour + 30*time.Minute)), TxHash: "0x1", Amount: 1.5}})
+
+	gaps := ledger.Gaps()
+	if len(gaps) != 1 || math.Abs(gaps[0].Earnings-0.4) > 1e-9 {
+		t.Fatalf("expected a single gap earning 0.4, got %+v", gaps)
+	}
+	daily := ledger.Daily(loc)
+	if len(daily) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(daily))
+	}
+	if daily[0].Earnings != 0 || daily[0].Complete {
+		t.Errorf("first day should be an incomplete gap day, got %+v", daily[0])
+	}
+	if math.Abs(daily[1].Earnings-0.5) > 1e-9 || daily[1].Payouts != 1.5 || daily[1].Coverage != 2*time.Hour {
+		t.Errorf("unexpected second day %+v", daily[1])
+	}
+}
@@ -0,0 +1,158 @@
+package npapi
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// megahash converts hashrates given in MH/s to H/s.
+const megahash = 1e6
+
+// LuckWindow compares the observed share count of a window with the count expected from the hashrate.
+type LuckWindow struct {
+	// Window start
+	Date time.Time
+	// Expected number of shares
+	Expected float64
+	// Observed number of shares
+	Observed uint
+	// Luck (Observed / Expected)
+	Luck float64
+	// Confidence interval of the luck
+	Lower, Upper float64
+	// Two-sided probability of an observation at least this extreme
+	PValue float64
+	// Significant is true if the deviation is not explained by chance at the chosen confidence
+	Significant bool
+}
+
+// poissonLogPMF computes the logarithm of P(X = k) for X ~ Poisson(lambda).
+func poissonLogPMF(k uint, lambda float64) float64 {
+	lg, _ := math.Lgamma(float64(k) + 1)
+	return float64(k)*math.Log(lambda) - lambda - lg
+}
+
+// poissonCDF computes P(X <= k) for X ~ Poisson(lambda).
+func poissonCDF(k uint, lambda float64) float64 {
+	if lambda <= 0 {
+		return 1
+	}
+	var sum float64
+	for i := uint(0); i <= k; i++ {
+		sum += math.Exp(poissonLogPMF(i, lambda))
+	}
+	return math.Min(sum, 1)
+}
+
+// poissonPValue computes the two-sided probability of observing a count at least as extreme as k.
+func poissonPValue(k uint, lambda float64) float64 {
+	lower := poissonCDF(k, lambda)
+	upper := 1.0
+	if k > 0 {
+		upper = 1 - poissonCDF(k-1, lambda)
+	}
+	return math.Min(1, 2*math.Min(lower, upper))
+}
+
+// poissonInterval computes the exact (Garwood) confidence interval of a Poisson mean given k observed events.
+func poissonInterval(k uint, confidence float64) (float64, float64) {
+	alpha := (1 - confidence) / 2
+	bisect := func(f func(float64) bool) float64 {
+		lo, hi := 0.0, float64(k)+20*math.Sqrt(float64(k)+1)+20
+		for i := 0; i < 100; i++ {
+			mid := (lo + hi) / 2
+			if f(mid) {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		return (lo + hi) / 2
+	}
+	var lower float64
+	if k > 0 {
+		lower = bisect(func(l float64) bool { return 1-poissonCDF(k-1, l) >= alpha })
+	}
+	upper := bisect(func(l float64) bool { return poissonCDF(k, l) <= alpha })
+	return lower, upper
+}
+
+// ExpectedShares computes the number of shares a hashrate [MH/s] is expected to find in the given duration.
+func ExpectedShares(hashrate, difficulty float64, d time.Duration) float64 {
+	return hashrate * megahash * d.Seconds() / difficulty
+}
+
+// ShareLuck groups the share history into windows of the given size and compares the observed shares with those
+// expected from the hashrate [MH/s] and share difficulty, using Poisson statistics at the given confidence level.
+func ShareLuck(shares []ShareItem, hashrate, difficulty float64, window time.Duration, confidence float64) ([]LuckWindow, error) {
+	if err := checkConfidence(confidence); err != nil {
+		return nil, err
+	}
+	if window < ShareInterval {
+		window = ShareInterval
+	}
+	counts := make(map[int64]uint)
+	buckets := make(map[int64]map[int64]bool)
+	for _, s := range shares {
+		start := time.Time(s.Date).Truncate(window).Unix()
+		if buckets[start] == nil {
+			buckets[start] = make(map[int64]bool)
+		}
+		buckets[start][time.Time(s.Date).Truncate(ShareInterval).Unix()] = true
+		counts[start] += s.Shares
+	}
+	var windows []LuckWindow
+	for start, observed := range counts {
+		// only the covered part of the window contributes to the expectation
+		expected := ExpectedShares(hashrate, difficulty, time.Duration(len(buckets[start]))*ShareInterval)
+		w := LuckWindow{
+			Date:     time.Unix(start, 0),
+			Expected: expected,
+			Observed: observed,
+			PValue:   1,
+		}
+		if expected > 0 {
+			lower, upper := poissonInterval(observed, confidence)
+			w.Luck = float64(observed) / expected
+			w.Lower, w.Upper = lower/expected, upper/expected
+			w.PValue = poissonPValue(observed, expected)
+			w.Significant = w.PValue < 1-confidence
+		}
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Date.Before(windows[j].Date) })
+	return windows, nil
+}
+
+// AccountShareLuck fetches the share history and average hashrate of the last day and computes the share luck.
+func AccountShareLuck(addr string, difficulty float64, window time.Duration, confidence float64) ([]LuckWindow, error) {
+	if err := checkConfidence(confidence); err != nil {
+		return nil, err
+	}
+	shares, err := ShareHistory(addr)
+	if err != nil {
+		return nil, err
+	}
+	hashrates, err := AverageHashrate(addr)
+	if err != nil {
+		return nil, err
+	}
+	return ShareLuck(shares, hashrates.LastDay, difficulty, window, confidence)
+}
+
+// WorkerShareLuck fetches the share history and average hashrate of the last day of a worker and computes the share luck.
+func WorkerShareLuck(addr, worker string, difficulty float64, window time.Duration, confidence float64) ([]LuckWindow, error) {
+	if err := checkConfidence(confidence); err != nil {
+		return nil, err
+	}
+	shares, err := WorkerShareHistory(addr, worker)
+	if err != nil {
+		return nil, err
+	}
+	hashrates, err := WorkerAverageHashrate(addr, worker)
+	if err != nil {
+		return nil, err
+	}
+	return ShareLuck(shares, hashrates.LastDay, difficulty, window, confidence)
+}
@@ -0,0 +1,217 @@
+package npapi
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// MergePolicy resolves conflicting values sharing the same date.
+type MergePolicy int
+
+const (
+	// KeepLatest keeps the value of the window merged last
+	KeepLatest MergePolicy = iota
+	// KeepFirst keeps the value of the window merged first
+	KeepFirst
+	// KeepMax keeps the larger value
+	KeepMax
+	// KeepMin keeps the smaller value
+	KeepMin
+)
+
+// resolve picks the value to keep when two windows disagree.
+func (policy MergePolicy) resolve(old, new float64) float64 {
+	switch policy {
+	case KeepFirst:
+		return old
+	case KeepMax:
+		return math.Max(old, new)
+	case KeepMin:
+		return math.Min(old, new)
+	}
+	return new
+}
+
+// FillPolicy selects how missing points of a continuous series are filled.
+type FillPolicy int
+
+const (
+	// FillZero inserts zero values
+	FillZero FillPolicy = iota
+	// FillPrevious repeats the previous value
+	FillPrevious
+)
+
+// mergePoints merges windows of points, de-duplicating them by date.
+func mergePoints(policy MergePolicy, windows ...[]Point) []Point {
+	values := make(map[int64]float64)
+	for _, w := range windows {
+		for _, p := range w {
+			key := p.Date.Unix()
+			if old, ok := values[key]; ok {
+				values[key] = policy.resolve(old, p.Value)
+				continue
+			}
+			values[key] = p.Value
+		}
+	}
+	points := make([]Point, 0, len(values))
+	for key, value := range values {
+		points = append(points, Point{Date: time.Unix(key, 0), Value: value})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	return points
+}
+
+// MergeHistory merges overlapping hashrate history windows into a single history ordered by date.
+func MergeHistory(policy MergePolicy, windows ...[]HistoryItem) []HistoryItem {
+	converted := make([][]Point, len(windows))
+	for i, w := range windows {
+		converted[i] = HistoryPoints(w)
+	}
+	points := mergePoints(policy, converted...)
+	history := make([]HistoryItem, len(points))
+	for i, p := range points {
+		history[i] = HistoryItem{Date: Time(p.Date), Hashrate: p.Value}
+	}
+	return history
+}
+
+// MergeShares merges overlapping share history windows into a single history ordered by date.
+func MergeShares(policy MergePolicy, windows ...[]ShareItem) []ShareItem {
+	converted := make([][]Point, len(windows))
+	for i, w := range windows {
+		converted[i] = SharePoints(w)
+	}
+	points := mergePoints(policy, converted...)
+	shares := make([]ShareItem, len(points))
+	for i, p := range points {
+		shares[i] = ShareItem{Date: Time(p.Date), Shares: uint(p.Value)}
+	}
+	return shares
+}
+
+// SeriesGap is an interval without items in a sampled history.
+type SeriesGap struct {
+	// Last item before the gap
+	From time.Time
+	// First item after the gap
+	To time.Time
+}
+
+// findGaps returns the intervals between consecutive dates that exceed the expected step.
+func findGaps(dates []time.Time, step time.Duration) []SeriesGap {
+	var gaps []SeriesGap
+	for i := 1; i < len(dates); i++ {
+		if dates[i].Sub(dates[i-1]) > step {
+			gaps = append(gaps, SeriesGap{From: dates[i-1], To: dates[i]})
+		}
+	}
+	return gaps
+}
+
+// HistoryGaps returns the intervals in which a history sampled every step has missing items.
+func HistoryGaps(history []HistoryItem, step time.Duration) []SeriesGap {
+	dates := make([]time.Time, len(history))
+	for i, h := range history {
+		dates[i] = time.Time(h.Date)
+	}
+	return findGaps(dates, step)
+}
+
+// ShareGaps returns the intervals in which a share history sampled every step has missing items.
+func ShareGaps(shares []ShareItem, step time.Duration) []SeriesGap {
+	dates := make([]time.Time, len(shares))
+	for i, s := range shares {
+		dates[i] = time.Time(s.Date)
+	}
+	return findGaps(dates, step)
+}
+
+// fillPoints inserts points into gaps so that consecutive points are exactly one step apart.
+func fillPoints(points []Point, step time.Duration, policy FillPolicy) []Point {
+	if len(points) == 0 || step <= 0 {
+		return points
+	}
+	filled := []Point{points[0]}
+	for _, p := range points[1:] {
+		previous := filled[len(filled)-1]
+		for next := previous.Date.Add(step); next.Before(p.Date); next = next.Add(step) {
+			value := 0.0
+			if policy == FillPrevious {
+				value = previous.Value
+			}
+			filled = append(filled, Point{Date: next, Value: value})
+		}
+		filled = append(filled, p)
+	}
+	return filled
+}
+
+// ContinuousHistory fills the gaps of an ordered history so that items are exactly one step apart.
+func ContinuousHistory(history []HistoryItem, step time.Duration, policy FillPolicy) []HistoryItem {
+	points := fillPoints(HistoryPoints(history), step, policy)
+	result := make([]HistoryItem, len(points))
+	for i, p := range points {
+		result[i] = HistoryItem{Date: Time(p.Date), Hashrate: p.Value}
+	}
+	return result
+}
+
+// ContinuousShares fills the gaps of an ordered share history so that items are exactly one step apart.
+func ContinuousShares(shares []ShareItem, step time.Duration, policy FillPolicy) []ShareItem {
+	points := fillPoints(SharePoints(shares), step, policy)
+	result := make([]ShareItem, len(points))
+	for i, p := range points {
+		result[i] = ShareItem{Date: Time(p.Date), Shares: uint(p.Value)}
+	}
+	return result
+}
+
+// Backfill fetches a window of points and merges it into the series of the store. It reports whether the
+// stored series now reaches back to the start date. Since the API only serves its latest window, Backfill
+// is meant to be called periodically; history the API no longer serves cannot be recovered.
+func (s *Store) Backfill(series string, start time.Time, policy MergePolicy, fetch func() ([]Point, error)) (bool, error) {
+	window, err := fetch()
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, err := s.read(series)
+	if err != nil {
+		return false, err
+	}
+	merged := mergePoints(policy, stored, window)
+	var fresh []Point
+	known := make(map[int64]float64, len(stored))
+	for _, p := range stored {
+		known[p.Date.Unix()] = p.Value
+	}
+	for _, p := range merged {
+		if v, ok := known[p.Date.Unix()]; !ok || v != p.Value {
+			fresh = append(fresh, p)
+		}
+	}
+	if err := s.write(series, fresh); err != nil {
+		return false, err
+	}
+	return len(merged) > 0 && !merged[0].Date.After(start), nil
+}
+
+// BackfillHistory merges the latest hashrate history of the account into the store.
+func (s *Store) BackfillHistory(addr string, start time.Time, policy MergePolicy) (bool, error) {
+	return s.Backfill(HashrateSeries(addr), start, policy, func() ([]Point, error) {
+		history, err := HashrateHistory(addr)
+		return HistoryPoints(history), err
+	})
+}
+
+// BackfillWorkerShares merges the latest share history of the worker into the store.
+func (s *Store) BackfillWorkerShares(addr, worker string, start time.Time, policy MergePolicy) (bool, error) {
+	return s.Backfill(WorkerSharesSeries(addr, worker), start, policy, func() ([]Point, error) {
+		shares, err := WorkerShareHistory(addr, worker)
+		return SharePoints(shares), err
+	})
+}
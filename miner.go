@@ -16,6 +16,10 @@ func (t *Time) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+func (t Time) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(time.Time(t).Unix(), 10)), nil
+}
+
 // Payment is a nanopool.org payment.
 type Payment struct {
 	// Payment date
@@ -0,0 +1,222 @@
+package npapi
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// NetworkPoint stores derived network metrics of a single block.
+type NetworkPoint struct {
+	// Block date
+	Date time.Time
+	// Block difficulty
+	Difficulty float64
+	// Block time [s]
+	BlockTime float64
+	// Implied network hashrate [MH/s]
+	Hashrate float64
+	// Moving averages of difficulty and hashrate [MH/s]
+	AverageDifficulty, AverageHashrate float64
+}
+
+// NetworkTrend stores the network difficulty and hashrate trend over a window of blocks.
+type NetworkTrend struct {
+	// Analyzed period
+	From, To time.Time
+	// Per-block metrics, ordered by date
+	Points []NetworkPoint
+	// Moving average of the difficulty and hashrate [MH/s] at the end of the period
+	Difficulty, Hashrate float64
+	// Exponential growth rate per day of difficulty and hashrate, zero unless significant
+	DifficultyGrowth, HashrateGrowth float64
+}
+
+const (
+	// TrendWindow is the default number of blocks averaged into the network difficulty and hashrate.
+	TrendWindow = 100
+	// TrendSpan is the default period of block stats analyzed for network trends.
+	TrendSpan = 48 * time.Hour
+	// minTrendSpan is the shortest period a growth rate is fitted over.
+	minTrendSpan = 24 * time.Hour
+	// trendSignificance is the number of standard errors a growth rate must differ from zero by.
+	trendSignificance = 3
+)
+
+// linearRegression fits y = slope * x + intercept using least squares.
+func linearRegression(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	if n == 0 {
+		return 0, 0
+	}
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	denominator := n*sxx - sx*sx
+	if denominator == 0 {
+		return 0, sy / n
+	}
+	slope = (n*sxy - sx*sy) / denominator
+	return slope, (sy - slope*sx) / n
+}
+
+// growthRate fits an exponential to the values and returns its growth rate per day. Rates fitted over less
+// than minTrendSpan or not differing significantly from zero are reported as zero, since extrapolating
+// them for weeks would mostly project noise.
+func growthRate(dates []time.Time, values []float64) float64 {
+	var xs, ys []float64
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		xs = append(xs, dates[i].Sub(dates[0]).Hours()/24)
+		ys = append(ys, math.Log(v))
+	}
+	if len(xs) < 3 || xs[len(xs)-1]-xs[0] < minTrendSpan.Hours()/24 {
+		return 0
+	}
+	slope, intercept := linearRegression(xs, ys)
+	// standard error of the slope
+	var mean, sxx, residuals float64
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for i, x := range xs {
+		sxx += (x - mean) * (x - mean)
+		r := ys[i] - (slope*x + intercept)
+		residuals += r * r
+	}
+	if sxx == 0 {
+		return 0
+	}
+	stderr := math.Sqrt(residuals / float64(len(xs)-2) / sxx)
+	if math.Abs(slope) < trendSignificance*stderr {
+		return 0
+	}
+	return slope
+}
+
+// NetworkHashrate derives the implied network hashrate of each block and moving averages over the given number of blocks.
+func NetworkHashrate(stats []BlockStatItem, window int) []NetworkPoint {
+	if window <= 0 {
+		window = 1
+	}
+	stats = append([]BlockStatItem(nil), stats...)
+	sort.SliceStable(stats, func(i, j int) bool { return time.Time(stats[i].Date).Before(time.Time(stats[j].Date)) })
+	points := make([]NetworkPoint, len(stats))
+	var difficulties, hashrates float64
+	for i, s := range stats {
+		p := NetworkPoint{
+			Date:       time.Time(s.Date),
+			Difficulty: float64(s.Difficulty),
+			BlockTime:  s.BlockTime,
+		}
+		if s.BlockTime > 0 {
+			p.Hashrate = p.Difficulty / s.BlockTime / megahash
+		}
+		difficulties += p.Difficulty
+		hashrates += p.Hashrate
+		if i >= window {
+			difficulties -= points[i-window].Difficulty
+			hashrates -= points[i-window].Hashrate
+		}
+		n := float64(window)
+		if i < window {
+			n = float64(i + 1)
+		}
+		p.AverageDifficulty, p.AverageHashrate = difficulties/n, hashrates/n
+		points[i] = p
+	}
+	return points
+}
+
+// AnalyzeNetworkTrend derives the network difficulty and hashrate trend from block stats, using moving averages
+// over the given number of blocks. Growth rates are fitted to the per-block values rather than to the moving
+// averages, whose first window is biased and whose autocorrelation would overstate their significance.
+func AnalyzeNetworkTrend(stats []BlockStatItem, window int) NetworkTrend {
+	points := NetworkHashrate(stats, window)
+	var trend NetworkTrend
+	if len(points) == 0 {
+		return trend
+	}
+	last := points[len(points)-1]
+	trend.From, trend.To = points[0].Date, last.Date
+	trend.Points = points
+	trend.Difficulty, trend.Hashrate = last.AverageDifficulty, last.AverageHashrate
+	dates := make([]time.Time, len(points))
+	difficulties := make([]float64, len(points))
+	hashrates := make([]float64, len(points))
+	for i, p := range points {
+		dates[i], difficulties[i], hashrates[i] = p.Date, p.Difficulty, p.Hashrate
+	}
+	trend.DifficultyGrowth = growthRate(dates, difficulties)
+	trend.HashrateGrowth = growthRate(dates, hashrates)
+	return trend
+}
+
+// ProjectDifficulty projects the network difficulty at the given date.
+func (t NetworkTrend) ProjectDifficulty(at time.Time) float64 {
+	return t.Difficulty * math.Exp(t.DifficultyGrowth*at.Sub(t.To).Hours()/24)
+}
+
+// scaleEarnings multiplies all values of the earnings item by f.
+func scaleEarnings(item EarningsItem, f float64) EarningsItem {
+	return EarningsItem{
+		Coins:    item.Coins * f,
+		Bitcoins: item.Bitcoins * f,
+		Dollars:  item.Dollars * f,
+		Yuan:     item.Yuan * f,
+		Euros:    item.Euros * f,
+		Rubles:   item.Rubles * f,
+	}
+}
+
+// ProjectEarnings recalculates the daily earnings of the report for each of the next days against the projected difficulty.
+// Prices are kept constant.
+func (t NetworkTrend) ProjectEarnings(report EarningsReport, days int) []EarningsItem {
+	earnings := make([]EarningsItem, days)
+	for i := range earnings {
+		projected := t.ProjectDifficulty(t.To.Add(time.Duration(i+1) * 24 * time.Hour))
+		factor := 1.0
+		if projected > 0 {
+			factor = t.Difficulty / projected
+		}
+		earnings[i] = scaleEarnings(report.PerDay, factor)
+	}
+	return earnings
+}
+
+// FetchNetworkTrend fetches the block stats of the given period and derives their trend using moving averages
+// over the given number of blocks.
+func FetchNetworkTrend(span time.Duration, window int) (NetworkTrend, error) {
+	it := NewBlockStatIterator()
+	it.PageSize = 1000
+	it.After = time.Now().Add(-span)
+	var stats []BlockStatItem
+	for it.Next() {
+		stats = append(stats, it.Stat().BlockStatItem)
+	}
+	if err := it.Err(); err != nil {
+		return NetworkTrend{}, err
+	}
+	return AnalyzeNetworkTrend(stats, window), nil
+}
+
+// EarningsOutlook fetches the block stats of the last TrendSpan and the approximated earnings of the
+// hashrate [MH/s] and projects the daily earnings for the next days.
+func EarningsOutlook(hashrate float64, days int) ([]EarningsItem, error) {
+	trend, err := FetchNetworkTrend(TrendSpan, TrendWindow)
+	if err != nil {
+		return nil, err
+	}
+	report, err := ApproximatedEarnings(hashrate)
+	if err != nil {
+		return nil, err
+	}
+	return trend.ProjectEarnings(report, days), nil
+}
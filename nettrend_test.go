@@ -0,0 +1,41 @@
+package npapi
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// syntheticStats creates block stats with 15 s blocks whose difficulty grows at the given daily rate with 2% noise.
+func syntheticStats(rng *rand.Rand, span time.Duration, growth float64) []BlockStatItem {
+	start := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	var stats []BlockStatItem
+	for date := start; date.Before(start.Add(span)); date = date.Add(15 * time.Second) {
+		days := date.Sub(start).Hours() / 24
+		difficulty := 1e15 * math.Exp(growth*days) * (1 + 0.02*rng.NormFloat64())
+		stats = append(stats, BlockStatItem{Date: Time(date), Difficulty: uint64(difficulty), BlockTime: rng.ExpFloat64() * 15})
+	}
+	return stats
+}
+
+func TestNetworkTrendStationary(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		for _, span := range []time.Duration{4 * time.Hour, TrendSpan} {
+			trend := AnalyzeNetworkTrend(syntheticStats(rng, span, 0), TrendWindow)
+			factor := trend.Difficulty / trend.ProjectDifficulty(trend.To.AddDate(0, 0, 30))
+			if math.Abs(factor-1) > 0.05 {
+				t.Errorf("seed %d, span %s: expected a day 30 factor of about 1, got %f", seed, span, factor)
+			}
+		}
+	}
+}
+
+func TestNetworkTrendGrowth(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	trend := AnalyzeNetworkTrend(syntheticStats(rng, TrendSpan, 0.02), TrendWindow)
+	if math.Abs(trend.DifficultyGrowth-0.02) > 0.002 {
+		t.Errorf("expected a daily difficulty growth of 0.02, got %f", trend.DifficultyGrowth)
+	}
+}
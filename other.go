@@ -37,6 +37,15 @@ type PriceReport struct {
 
 // ApproximatedEarnings calculates the approximated earnings projected by the hashrate.
 func ApproximatedEarnings(hashrate float64) (EarningsReport, error) {
+	return approximatedEarnings(apiAddress, hashrate)
+}
+
+// Prices fetches a price report from the server, storing the current exchange rates for ETH.
+func Prices() (PriceReport, error) {
+	return prices(apiAddress)
+}
+
+func approximatedEarnings(base string, hashrate float64) (EarningsReport, error) {
 	jsonReport := map[string]struct {
 		Coins    float64 `json:"coins"`
 		Bitcoins float64 `json:"bitcoins"`
@@ -45,7 +54,7 @@ func ApproximatedEarnings(hashrate float64) (EarningsReport, error) {
 		Euros    float64 `json:"euros"`
 		Rubles   float64 `json:"rubles"`
 	}{}
-	if err := fetch(&jsonReport, approximatedEarningsEndpoint, hashrate); err != nil {
+	if err := fetchFrom(base, &jsonReport, approximatedEarningsEndpoint, hashrate); err != nil {
 		return EarningsReport{}, err
 	}
 	return EarningsReport{
@@ -57,8 +66,7 @@ func ApproximatedEarnings(hashrate float64) (EarningsReport, error) {
 	}, nil
 }
 
-// Prices fetches a price report from the server, storing the current exchange rates for ETH.
-func Prices() (PriceReport, error) {
+func prices(base string) (PriceReport, error) {
 	jsonPrices := struct {
 		USDollar float64 `json:"price_usd"`
 		Euro     float64 `json:"price_eur"`
@@ -66,7 +74,7 @@ func Prices() (PriceReport, error) {
 		Yuan     float64 `json:"price_cny"`
 		Bitcoins float64 `json:"price_btc"`
 	}{}
-	if err := fetch(&jsonPrices, pricesEndpoint); err != nil {
+	if err := fetchFrom(base, &jsonPrices, pricesEndpoint); err != nil {
 		return PriceReport{}, err
 	}
 	return PriceReport(jsonPrices), nil
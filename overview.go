@@ -0,0 +1,92 @@
+package npapi
+
+import "sync"
+
+// PoolOverviewReport stores the pool size and concentration metrics.
+type PoolOverviewReport struct {
+	// Pool hashrate [MH/s]
+	Hashrate float64
+	// Number of active miners and workers
+	Miners, Workers uint
+	// Average hashrate per miner and per worker [MH/s]
+	HashratePerMiner, HashratePerWorker float64
+	// Top miners
+	TopMiners []User
+	// Share of the pool hashrate held by the top miners
+	TopShare float64
+	// Herfindahl index of the top miners' shares
+	Herfindahl float64
+	// Own account hashrate [MH/s] and share of the pool hashrate
+	OwnHashrate, OwnShare float64
+}
+
+// PoolOverview fetches the pool hashrate, miner and worker counts and top miners concurrently and derives
+// concentration metrics. If addr is not empty, the account's share of the pool is included.
+func PoolOverview(addr string) (PoolOverviewReport, error) {
+	var (
+		report PoolOverviewReport
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		first  error
+	)
+	run := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				mu.Lock()
+				if first == nil {
+					first = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	run(func() (err error) {
+		report.Hashrate, err = PoolHashrate()
+		return err
+	})
+	run(func() (err error) {
+		report.Miners, err = NumberOfMiners()
+		return err
+	})
+	run(func() (err error) {
+		report.Workers, err = NumberOfWorkers()
+		return err
+	})
+	run(func() (err error) {
+		report.TopMiners, err = TopMiners()
+		return err
+	})
+	if addr != "" {
+		run(func() (err error) {
+			report.OwnHashrate, err = CurrentHashrate(addr)
+			return err
+		})
+	}
+	wg.Wait()
+	if first != nil {
+		return PoolOverviewReport{}, first
+	}
+	report.derive()
+	return report, nil
+}
+
+// derive computes the averages and concentration metrics from the fetched values.
+func (r *PoolOverviewReport) derive() {
+	if r.Miners > 0 {
+		r.HashratePerMiner = r.Hashrate / float64(r.Miners)
+	}
+	if r.Workers > 0 {
+		r.HashratePerWorker = r.Hashrate / float64(r.Workers)
+	}
+	if r.Hashrate <= 0 {
+		return
+	}
+	for _, m := range r.TopMiners {
+		share := m.Hashrate / r.Hashrate
+		r.TopShare += share
+		r.Herfindahl += share * share
+	}
+	r.OwnShare = r.OwnHashrate / r.Hashrate
+}
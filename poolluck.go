@@ -0,0 +1,142 @@
+package npapi
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// BlockEffort stores the work the pool spent on finding a block.
+type BlockEffort struct {
+	// Found block
+	Block BlockItem
+	// Time since the previous block found by the pool
+	Interval time.Duration
+	// Expected hashes spent relative to the block difficulty
+	Effort float64
+}
+
+// PoolLuckReport stores block-finding statistics of the pool.
+type PoolLuckReport struct {
+	// Analyzed period
+	From, To time.Time
+	// Number of blocks found in the period
+	Found int
+	// Number of blocks expected in the period
+	Expected float64
+	// Luck (Found / Expected)
+	Luck float64
+	// Effort per block, ordered by block number
+	Efforts []BlockEffort
+	// Average effort per block
+	AverageEffort float64
+	// Distribution of the time between blocks
+	MeanInterval, MedianInterval, P90Interval, MaxInterval time.Duration
+}
+
+// LuckPoint stores the pool luck over a window of blocks ending at a specific date.
+type LuckPoint struct {
+	// Date of the last block in the window
+	Date time.Time
+	// Luck (Found / Expected)
+	Luck float64
+}
+
+// percentile returns the p-th percentile (0 <= p <= 1) of sorted values using linear interpolation.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(pos)), int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// sortedBlocks returns a copy of the blocks ordered by ascending number.
+func sortedBlocks(blocks []BlockItem) []BlockItem {
+	sorted := append([]BlockItem(nil), blocks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Number < sorted[j].Number })
+	return sorted
+}
+
+// blockEfforts computes the effort of each block relative to its predecessor.
+func blockEfforts(blocks []BlockItem, poolHashrate float64) []BlockEffort {
+	var efforts []BlockEffort
+	for i := 1; i < len(blocks); i++ {
+		interval := time.Time(blocks[i].Date).Sub(time.Time(blocks[i-1].Date))
+		effort := BlockEffort{Block: blocks[i], Interval: interval}
+		if blocks[i].Difficulty > 0 {
+			effort.Effort = poolHashrate * megahash * interval.Seconds() / float64(blocks[i].Difficulty)
+		}
+		efforts = append(efforts, effort)
+	}
+	return efforts
+}
+
+// AnalyzePoolLuck computes expected and actual blocks, effort per block and the distribution of the time
+// between blocks found by a pool with the given hashrate [MH/s]. The first block only marks the start of the period.
+func AnalyzePoolLuck(blocks []BlockItem, poolHashrate float64) PoolLuckReport {
+	blocks = sortedBlocks(blocks)
+	var report PoolLuckReport
+	if len(blocks) == 0 {
+		return report
+	}
+	report.From, report.To = time.Time(blocks[0].Date), time.Time(blocks[len(blocks)-1].Date)
+	report.Efforts = blockEfforts(blocks, poolHashrate)
+	report.Found = len(report.Efforts)
+	if report.Found == 0 {
+		return report
+	}
+	intervals := make([]float64, report.Found)
+	for i, e := range report.Efforts {
+		report.Expected += e.Effort
+		intervals[i] = float64(e.Interval)
+	}
+	report.AverageEffort = report.Expected / float64(report.Found)
+	if report.Expected > 0 {
+		report.Luck = float64(report.Found) / report.Expected
+	}
+	sort.Float64s(intervals)
+	report.MeanInterval = report.To.Sub(report.From) / time.Duration(report.Found)
+	report.MedianInterval = time.Duration(percentile(intervals, 0.5))
+	report.P90Interval = time.Duration(percentile(intervals, 0.9))
+	report.MaxInterval = time.Duration(intervals[len(intervals)-1])
+	return report
+}
+
+// RollingPoolLuck computes the pool luck over a sliding window of the given number of blocks.
+func RollingPoolLuck(blocks []BlockItem, poolHashrate float64, window int) []LuckPoint {
+	efforts := blockEfforts(sortedBlocks(blocks), poolHashrate)
+	if window <= 0 || len(efforts) < window {
+		return nil
+	}
+	var points []LuckPoint
+	var sum float64
+	for i, e := range efforts {
+		sum += e.Effort
+		if i >= window {
+			sum -= efforts[i-window].Effort
+		}
+		if i < window-1 || sum <= 0 {
+			continue
+		}
+		points = append(points, LuckPoint{
+			Date: time.Time(e.Block.Date),
+			Luck: float64(window) / sum,
+		})
+	}
+	return points
+}
+
+// PoolLuck fetches the latest blocks and the pool hashrate and analyzes the pool luck.
+func PoolLuck(count uint) (PoolLuckReport, error) {
+	blocks, err := Blocks(0, count)
+	if err != nil {
+		return PoolLuckReport{}, err
+	}
+	hashrate, err := PoolHashrate()
+	if err != nil {
+		return PoolLuckReport{}, err
+	}
+	return AnalyzePoolLuck(blocks, hashrate), nil
+}
@@ -0,0 +1,305 @@
+package npapi
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PriceRecord stores the ETH exchange rates at a specific point in time.
+type PriceRecord struct {
+	// Record date
+	Date time.Time
+	// Exchange rates
+	Prices PriceReport
+}
+
+// PriceHistory is a list of price records ordered by date.
+type PriceHistory []PriceRecord
+
+var priceHistoryHeader = []string{"date", "usd", "eur", "rur", "cny", "btc"}
+
+// parseDate parses unix timestamps, RFC 3339 timestamps and plain dates.
+func parseDate(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// ReadPriceHistoryCSV reads a price history from CSV. The first row must be a header naming
+// the columns date, usd, eur, rur, cny and btc; missing currency columns are left empty.
+func ReadPriceHistoryCSV(r io.Reader) (PriceHistory, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, errors.New("missing date column")
+	}
+	var history PriceHistory
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		date, err := parseDate(row[columns["date"]])
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]float64)
+		for _, name := range priceHistoryHeader[1:] {
+			i, ok := columns[name]
+			if !ok || i >= len(row) || row[i] == "" {
+				continue
+			}
+			if values[name], err = strconv.ParseFloat(row[i], 64); err != nil {
+				return nil, err
+			}
+		}
+		history = append(history, PriceRecord{
+			Date: date,
+			Prices: PriceReport{
+				USDollar: values["usd"],
+				Euro:     values["eur"],
+				Rubles:   values["rur"],
+				Yuan:     values["cny"],
+				Bitcoins: values["btc"],
+			},
+		})
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].Date.Before(history[j].Date) })
+	return history, nil
+}
+
+// WriteCSV writes the price history in the format understood by ReadPriceHistoryCSV.
+func (h PriceHistory) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(priceHistoryHeader); err != nil {
+		return err
+	}
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for _, r := range h {
+		record := []string{
+			strconv.FormatInt(r.Date.Unix(), 10),
+			format(r.Prices.USDollar),
+			format(r.Prices.Euro),
+			format(r.Prices.Rubles),
+			format(r.Prices.Yuan),
+			format(r.Prices.Bitcoins),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Record fetches the current prices and appends them to the history.
+func (h *PriceHistory) Record() error {
+	prices, err := Prices()
+	if err != nil {
+		return err
+	}
+	*h = append(*h, PriceRecord{Date: time.Now(), Prices: prices})
+	return nil
+}
+
+// PriceAt returns the ETH price in the currency at t, interpolating linearly between the surrounding
+// records. Outside the recorded range the nearest record is used. Records without a rate for the
+// currency are ignored.
+func (h PriceHistory) PriceAt(t time.Time, c Currency) (PriceQuote, error) {
+	var before, after *PriceRecord
+	var beforePrice, afterPrice float64
+	for i := range h {
+		price, err := h[i].Prices.In(c)
+		if err != nil {
+			return PriceQuote{}, err
+		}
+		if price <= 0 {
+			continue
+		}
+		if !h[i].Date.After(t) {
+			before, beforePrice = &h[i], price
+			continue
+		}
+		after, afterPrice = &h[i], price
+		break
+	}
+	switch {
+	case before == nil && after == nil:
+		return PriceQuote{}, fmt.Errorf("no %s price recorded", c)
+	case before == nil:
+		return PriceQuote{Price: afterPrice, Staleness: after.Date.Sub(t)}, nil
+	case after == nil || before.Date.Equal(t):
+		return PriceQuote{Price: beforePrice, Staleness: t.Sub(before.Date)}, nil
+	}
+	span := after.Date.Sub(before.Date)
+	weight := float64(t.Sub(before.Date)) / float64(span)
+	staleness := t.Sub(before.Date)
+	if d := after.Date.Sub(t); d < staleness {
+		staleness = d
+	}
+	return PriceQuote{
+		Price:        beforePrice + (afterPrice-beforePrice)*weight,
+		Staleness:    staleness,
+		Interpolated: true,
+	}, nil
+}
+
+// merge adds records to the history, replacing records sharing the same date.
+func (h PriceHistory) merge(records PriceHistory) PriceHistory {
+	byDate := make(map[int64]PriceRecord, len(h)+len(records))
+	for _, r := range h {
+		byDate[r.Date.Unix()] = r
+	}
+	for _, r := range records {
+		byDate[r.Date.Unix()] = r
+	}
+	merged := make(PriceHistory, 0, len(byDate))
+	for _, r := range byDate {
+		merged = append(merged, r)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Date.Before(merged[j].Date) })
+	return merged
+}
+
+// PriceRecorder records price reports into a CSV file.
+type PriceRecorder struct {
+	// Path of the CSV file
+	Path string
+	// Recorded prices
+	History PriceHistory
+}
+
+// OpenPriceRecorder opens the price history stored in the given file, creating an empty history if the file does not exist.
+func OpenPriceRecorder(path string) (*PriceRecorder, error) {
+	recorder := &PriceRecorder{Path: path}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return recorder, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if recorder.History, err = ReadPriceHistoryCSV(file); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// save rewrites the CSV file with the recorded history.
+func (r *PriceRecorder) save() error {
+	tmp := r.Path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := r.History.WriteCSV(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.Path)
+}
+
+// Record fetches the current prices and stores them.
+func (r *PriceRecorder) Record() error {
+	var records PriceHistory
+	if err := records.Record(); err != nil {
+		return err
+	}
+	r.History = r.History.merge(records)
+	return r.save()
+}
+
+// Import reads historical prices from CSV in the format understood by ReadPriceHistoryCSV and stores them.
+// Imported records replace recorded ones with the same date.
+func (r *PriceRecorder) Import(reader io.Reader) error {
+	records, err := ReadPriceHistoryCSV(reader)
+	if err != nil {
+		return err
+	}
+	r.History = r.History.merge(records)
+	return r.save()
+}
+
+// Run records prices in the given interval until stop is closed or recording fails.
+func (r *PriceRecorder) Run(interval time.Duration, stop <-chan struct{}) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Record(); err != nil {
+			return err
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// PriceAt returns the recorded ETH price in the currency at t.
+func (r *PriceRecorder) PriceAt(t time.Time, c Currency) (PriceQuote, error) {
+	return r.History.PriceAt(t, c)
+}
+
+// PriceHistory joins the price series recorded by Poll in the interval [from, to] into a price history.
+func (s *Store) PriceHistory(from, to time.Time) (PriceHistory, error) {
+	records := make(map[int64]*PriceRecord)
+	for _, c := range PriceCurrencies {
+		points, err := s.Range(PriceSeries(c), from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range points {
+			r, ok := records[p.Date.Unix()]
+			if !ok {
+				r = &PriceRecord{Date: p.Date}
+				records[p.Date.Unix()] = r
+			}
+			switch c {
+			case USD:
+				r.Prices.USDollar = p.Value
+			case EUR:
+				r.Prices.Euro = p.Value
+			case RUB:
+				r.Prices.Rubles = p.Value
+			case CNY:
+				r.Prices.Yuan = p.Value
+			case BTC:
+				r.Prices.Bitcoins = p.Value
+			}
+		}
+	}
+	history := make(PriceHistory, 0, len(records))
+	for _, r := range records {
+		history = append(history, *r)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Date.Before(history[j].Date) })
+	return history, nil
+}
@@ -0,0 +1,178 @@
+package npapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Coin is a cryptocurrency mined on nanopool.org.
+type Coin struct {
+	// API symbol
+	Symbol string
+	// Coin name
+	Name string
+	// Mining algorithm
+	Algorithm string
+	// Hashrate unit expected by the approximated earnings endpoint
+	Unit string
+}
+
+// Coins lists the coins supported by nanopool.org.
+var Coins = []Coin{
+	{Symbol: "eth", Name: "Ethereum", Algorithm: "ethash", Unit: "MH/s"},
+	{Symbol: "etc", Name: "Ethereum Classic", Algorithm: "etchash", Unit: "MH/s"},
+	{Symbol: "zec", Name: "Zcash", Algorithm: "equihash", Unit: "Sol/s"},
+	{Symbol: "xmr", Name: "Monero", Algorithm: "randomx", Unit: "H/s"},
+	{Symbol: "rvn", Name: "Ravencoin", Algorithm: "kawpow", Unit: "MH/s"},
+	{Symbol: "ergo", Name: "Ergo", Algorithm: "autolykos", Unit: "MH/s"},
+	{Symbol: "cfx", Name: "Conflux", Algorithm: "octopus", Unit: "MH/s"},
+}
+
+func (c Coin) apiAddress() string {
+	return fmt.Sprintf(coinAPIAddress, c.Symbol)
+}
+
+// CoinApproximatedEarnings calculates the approximated earnings of the coin projected by the hashrate, given in the coin's unit.
+func CoinApproximatedEarnings(c Coin, hashrate float64) (EarningsReport, error) {
+	return approximatedEarnings(c.apiAddress(), hashrate)
+}
+
+// CoinPrices fetches the current exchange rates of the coin.
+func CoinPrices(c Coin) (PriceReport, error) {
+	return prices(c.apiAddress())
+}
+
+// Rig is a mining machine with benchmarked hashrates.
+type Rig struct {
+	// Rig name
+	Name string
+	// Hashrate per algorithm, given in the unit of the coins using the algorithm
+	Hashrates map[string]float64
+}
+
+// CoinQuote stores the daily earnings of a single hashrate unit and the price of a coin.
+type CoinQuote struct {
+	Coin Coin
+	// Coins earned per day and hashrate unit
+	CoinsPerUnit float64
+	// Coin price
+	Price float64
+}
+
+// CoinProfit stores the expected daily earnings of a rig mining a coin.
+type CoinProfit struct {
+	Coin Coin
+	// Rig hashrate for the coin's algorithm
+	Hashrate float64
+	// Coins earned per day
+	Coins float64
+	// Value of the coins earned per day
+	Value float64
+}
+
+// RigAdvice ranks the coins a rig can mine by daily value.
+type RigAdvice struct {
+	// Rig name
+	Rig string
+	// Coins, most profitable first
+	Ranking []CoinProfit
+}
+
+// RankCoins ranks the coins the rig has benchmarks for by the value of their daily earnings.
+func RankCoins(rig Rig, quotes []CoinQuote) RigAdvice {
+	advice := RigAdvice{Rig: rig.Name}
+	for _, q := range quotes {
+		hashrate, ok := rig.Hashrates[q.Coin.Algorithm]
+		if !ok || hashrate <= 0 {
+			continue
+		}
+		coins := q.CoinsPerUnit * hashrate
+		advice.Ranking = append(advice.Ranking, CoinProfit{
+			Coin:     q.Coin,
+			Hashrate: hashrate,
+			Coins:    coins,
+			Value:    coins * q.Price,
+		})
+	}
+	sort.SliceStable(advice.Ranking, func(i, j int) bool { return advice.Ranking[i].Value > advice.Ranking[j].Value })
+	return advice
+}
+
+// CoinError is a failure to fetch the quote of a single coin.
+type CoinError struct {
+	Coin Coin
+	Err  error
+}
+
+func (e CoinError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Coin.Symbol, e.Err)
+}
+
+// CoinErrors collects the coins whose quotes could not be fetched.
+type CoinErrors []CoinError
+
+func (e CoinErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("fetching %d coin quotes failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+// FetchCoinQuotes concurrently fetches the approximated earnings and prices of the coins, with prices in the given
+// currency. Coins whose quote cannot be fetched, e.g. because the coin was retired, are reported as a CoinErrors error
+// alongside the quotes of the other coins.
+func FetchCoinQuotes(coins []Coin, currency Currency) ([]CoinQuote, error) {
+	quotes := make([]CoinQuote, len(coins))
+	errs := make([]error, len(coins))
+	var wg sync.WaitGroup
+	for i, c := range coins {
+		wg.Add(1)
+		go func(i int, c Coin) {
+			defer wg.Done()
+			earnings, err := CoinApproximatedEarnings(c, 1)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			report, err := CoinPrices(c)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			price, err := report.In(currency)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			quotes[i] = CoinQuote{Coin: c, CoinsPerUnit: earnings.PerDay.Coins, Price: price}
+		}(i, c)
+	}
+	wg.Wait()
+	var fetched []CoinQuote
+	var failed CoinErrors
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, CoinError{Coin: coins[i], Err: err})
+			continue
+		}
+		fetched = append(fetched, quotes[i])
+	}
+	if len(failed) > 0 {
+		return fetched, failed
+	}
+	return fetched, nil
+}
+
+// AdviseRigs fetches quotes for all supported coins and ranks them for every rig, valued in the given currency.
+// Coins whose quote cannot be fetched are left out of the rankings and reported as a CoinErrors error.
+func AdviseRigs(rigs []Rig, currency Currency) ([]RigAdvice, error) {
+	quotes, err := FetchCoinQuotes(Coins, currency)
+	advice := make([]RigAdvice, len(rigs))
+	for i, rig := range rigs {
+		advice[i] = RankCoins(rig, quotes)
+	}
+	return advice, err
+}
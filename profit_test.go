@@ -0,0 +1,42 @@
+package npapi
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRankCoins(t *testing.T) {
+	eth, rvn, xmr := Coins[0], Coins[4], Coins[3]
+	quotes := []CoinQuote{
+		{Coin: eth, CoinsPerUnit: 0.0001, Price: 300},
+		{Coin: rvn, CoinsPerUnit: 2, Price: 0.1},
+		{Coin: xmr, CoinsPerUnit: 0.00001, Price: 100},
+	}
+	rig := Rig{Name: "rig1", Hashrates: map[string]float64{"ethash": 100, "kawpow": 40, "randomx": 0}}
+	advice := RankCoins(rig, quotes)
+	values := []struct {
+		symbol string
+		value  float64
+	}{{"rvn", 8}, {"eth", 3}}
+	if advice.Rig != "rig1" || len(advice.Ranking) != len(values) {
+		t.Fatalf("expected %d ranked coins, got %+v", len(values), advice)
+	}
+	for i, v := range values {
+		p := advice.Ranking[i]
+		if p.Coin.Symbol != v.symbol || math.Abs(p.Value-v.value) > 1e-9 {
+			t.Errorf("rank %d: expected %s worth %f, got %s worth %f", i, v.symbol, v.value, p.Coin.Symbol, p.Value)
+		}
+	}
+}
+
+func TestCoinErrors(t *testing.T) {
+	var err error = CoinErrors{{Coin: Coins[1], Err: errors.New("retired")}}
+	var failed CoinErrors
+	if !errors.As(err, &failed) || len(failed) != 1 || failed[0].Coin.Symbol != "etc" {
+		t.Errorf("expected the failed coins to be recoverable, got %v", err)
+	}
+	if err.Error() != "fetching 1 coin quotes failed: etc: retired" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
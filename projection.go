@@ -0,0 +1,84 @@
+package npapi
+
+import (
+	"sort"
+	"time"
+)
+
+// Projection stores the projected earnings of a hashrate over a period.
+type Projection struct {
+	// Worker ID, empty for the total
+	Worker string
+	// Hashrate [MH/s]
+	Hashrate float64
+	// Projected coins in ETH
+	Coins float64
+	// Projected value per currency
+	Values map[Currency]float64
+}
+
+// ProjectionReport stores the projected earnings of each worker and their total.
+type ProjectionReport struct {
+	// Projection period
+	Duration time.Duration
+	// Per-worker projections, ordered by worker ID
+	Workers []Projection
+	// Total projection
+	Total Projection
+}
+
+// project scales the daily earnings by the fraction of the reference hashrate and the number of days.
+func project(daily EarningsItem, referenceHashrate, hashrate, days float64) (float64, map[Currency]float64) {
+	factor := hashrate / referenceHashrate * days
+	values := make(map[Currency]float64, len(PriceCurrencies))
+	for _, c := range PriceCurrencies {
+		value, _ := daily.In(c)
+		values[c] = value * factor
+	}
+	return daily.Coins * factor, values
+}
+
+// Project scales the daily earnings of the report, approximated for the reference hashrate [MH/s], to the given
+// period and per-worker hashrates [MH/s].
+func Project(earnings EarningsReport, referenceHashrate float64, d time.Duration, hashrates map[string]float64) ProjectionReport {
+	report := ProjectionReport{Duration: d, Total: Projection{Values: make(map[Currency]float64)}}
+	if referenceHashrate <= 0 {
+		return report
+	}
+	days := d.Hours() / 24
+	for worker, hashrate := range hashrates {
+		p := Projection{Worker: worker, Hashrate: hashrate}
+		p.Coins, p.Values = project(earnings.PerDay, referenceHashrate, hashrate, days)
+		report.Workers = append(report.Workers, p)
+		report.Total.Hashrate += hashrate
+	}
+	sort.Slice(report.Workers, func(i, j int) bool { return report.Workers[i].Worker < report.Workers[j].Worker })
+	report.Total.Coins, report.Total.Values = project(earnings.PerDay, referenceHashrate, report.Total.Hashrate, days)
+	return report
+}
+
+// WorkerHashrates converts hashrate items to a map from worker ID to hashrate.
+func WorkerHashrates(items []HashrateItem) map[string]float64 {
+	hashrates := make(map[string]float64, len(items))
+	for _, item := range items {
+		hashrates[item.ID] = item.Hashrate
+	}
+	return hashrates
+}
+
+// ProjectEarnings fetches the approximated earnings of the total hashrate once and projects the earnings of
+// each worker over the given period.
+func ProjectEarnings(d time.Duration, hashrates map[string]float64) (ProjectionReport, error) {
+	var total float64
+	for _, hashrate := range hashrates {
+		total += hashrate
+	}
+	if total <= 0 {
+		return Project(EarningsReport{}, 0, d, hashrates), nil
+	}
+	earnings, err := ApproximatedEarnings(total)
+	if err != nil {
+		return ProjectionReport{}, err
+	}
+	return Project(earnings, total, d, hashrates), nil
+}
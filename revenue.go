@@ -0,0 +1,243 @@
+package npapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Ownership maps worker IDs to their owners, each associated with a fractional stake in the worker.
+type Ownership map[string]map[string]float64
+
+// Validate checks that no stake is negative and that the stakes in each worker add up to at most one.
+func (o Ownership) Validate() error {
+	for worker, stakes := range o {
+		var total float64
+		for owner, stake := range stakes {
+			if stake < 0 {
+				return fmt.Errorf("negative stake %f of %s in worker %s", stake, owner, worker)
+			}
+			total += stake
+		}
+		if total > 1+1e-9 {
+			return fmt.Errorf("stakes in worker %s add up to %f", worker, total)
+		}
+	}
+	return nil
+}
+
+// ShareMethod describes how the worker shares of a settlement were obtained.
+type ShareMethod int
+
+const (
+	// GivenShares were passed to SplitRevenue by the caller
+	GivenShares ShareMethod = iota
+	// HistoryShares were computed from the worker hashrate histories in the settlement period
+	HistoryShares
+	// AverageShares were computed from the average worker hashrates of the last day, regardless of the settlement period
+	AverageShares
+)
+
+func (m ShareMethod) String() string {
+	switch m {
+	case GivenShares:
+		return "given"
+	case HistoryShares:
+		return "history"
+	case AverageShares:
+		return "last day average"
+	}
+	return "unknown"
+}
+
+// Allocation is the part of a payment allocated to an owner through a worker.
+type Allocation struct {
+	// Payment transaction hash
+	TxHash string
+	// Payment date
+	Date time.Time
+	// Contributing worker
+	Worker string
+	// Allocated amount
+	Amount float64
+}
+
+// OwnerStatement lists all allocations made to a single owner.
+type OwnerStatement struct {
+	// Owner name
+	Owner string
+	// Allocations, ordered by date
+	Allocations []Allocation
+	// Total allocated amount
+	Total float64
+}
+
+// Settlement is the split of all payments made in a settlement period.
+type Settlement struct {
+	// Settlement period
+	From, To time.Time
+	// Share of each worker in the total hashrate
+	Shares map[string]float64
+	// Source of the worker shares
+	Method ShareMethod
+	// Fraction of the settlement period covered by the hashrate histories, set for HistoryShares
+	Coverage float64
+	// Per-owner statements, ordered by owner
+	Statements []OwnerStatement
+	// Amount that could not be allocated to any owner
+	Unallocated float64
+}
+
+// WorkerShares computes each worker's share of the total hashrate in the interval [from, to).
+func WorkerShares(histories map[string][]HistoryItem, from, to time.Time) map[string]float64 {
+	sums := make(map[string]float64)
+	var total float64
+	for worker, history := range histories {
+		for _, h := range history {
+			date := time.Time(h.Date)
+			if date.Before(from) || !date.Before(to) {
+				continue
+			}
+			sums[worker] += h.Hashrate
+			total += h.Hashrate
+		}
+	}
+	return normalizeShares(sums, total)
+}
+
+// historyCoverage returns the fraction of the interval [from, to) spanned by the history items inside it.
+func historyCoverage(histories map[string][]HistoryItem, from, to time.Time) float64 {
+	var first, last time.Time
+	for _, history := range histories {
+		for _, h := range history {
+			date := time.Time(h.Date)
+			if date.Before(from) || !date.Before(to) {
+				continue
+			}
+			if first.IsZero() || date.Before(first) {
+				first = date
+			}
+			if date.After(last) {
+				last = date
+			}
+		}
+	}
+	if first.IsZero() || !to.After(from) {
+		return 0
+	}
+	return float64(last.Sub(first)) / float64(to.Sub(from))
+}
+
+// averageShares computes each worker's share of the total hashrate from averaged hashrates.
+func averageShares(items []HashrateItem) map[string]float64 {
+	sums := make(map[string]float64)
+	var total float64
+	for _, item := range items {
+		sums[item.ID] += item.Hashrate
+		total += item.Hashrate
+	}
+	return normalizeShares(sums, total)
+}
+
+func normalizeShares(sums map[string]float64, total float64) map[string]float64 {
+	shares := make(map[string]float64, len(sums))
+	if total <= 0 {
+		return shares
+	}
+	for worker, sum := range sums {
+		shares[worker] = sum / total
+	}
+	return shares
+}
+
+// SplitRevenue allocates each confirmed payment made in the interval [from, to) to the owners according to the
+// worker shares. Unconfirmed payments are skipped, as in ValuePayments. The ownership must be valid.
+func SplitRevenue(payments []Payment, shares map[string]float64, owners Ownership, from, to time.Time) (Settlement, error) {
+	if err := owners.Validate(); err != nil {
+		return Settlement{}, err
+	}
+	settlement := Settlement{From: from, To: to, Shares: shares}
+	statements := make(map[string]*OwnerStatement)
+	for _, p := range payments {
+		date := time.Time(p.Date)
+		if !p.Confirmed || date.Before(from) || !date.Before(to) {
+			continue
+		}
+		allocated := 0.0
+		for worker, share := range shares {
+			for owner, stake := range owners[worker] {
+				amount := p.Amount * share * stake
+				s, ok := statements[owner]
+				if !ok {
+					s = &OwnerStatement{Owner: owner}
+					statements[owner] = s
+				}
+				s.Allocations = append(s.Allocations, Allocation{
+					TxHash: p.TxHash,
+					Date:   date,
+					Worker: worker,
+					Amount: amount,
+				})
+				s.Total += amount
+				allocated += amount
+			}
+		}
+		settlement.Unallocated += p.Amount - allocated
+	}
+	for _, s := range statements {
+		sort.SliceStable(s.Allocations, func(i, j int) bool {
+			a, b := s.Allocations[i], s.Allocations[j]
+			if a.Date.Equal(b.Date) {
+				return a.Worker < b.Worker
+			}
+			return a.Date.Before(b.Date)
+		})
+		settlement.Statements = append(settlement.Statements, *s)
+	}
+	sort.Slice(settlement.Statements, func(i, j int) bool {
+		return settlement.Statements[i].Owner < settlement.Statements[j].Owner
+	})
+	return settlement, nil
+}
+
+// Settle fetches the payments and worker hashrate histories of the account and splits the payments
+// made in the interval [from, to) among the owners. If no history covers the period, the workers'
+// average hashrates of the last day are used instead. The settlement records which shares were used
+// and how much of the period the histories cover.
+func Settle(addr string, owners Ownership, from, to time.Time) (*Settlement, error) {
+	payments, err := Payments(addr)
+	if err != nil {
+		return nil, err
+	}
+	workers, err := Workers(addr)
+	if err != nil {
+		return nil, err
+	}
+	histories := make(map[string][]HistoryItem, len(workers))
+	for _, w := range workers {
+		history, err := WorkerHashrateHistory(addr, w.ID)
+		if err != nil {
+			return nil, err
+		}
+		histories[w.ID] = history
+	}
+	method := HistoryShares
+	shares := WorkerShares(histories, from, to)
+	if len(shares) == 0 {
+		averages, err := WorkersAverageHashrate(addr)
+		if err != nil {
+			return nil, err
+		}
+		method = AverageShares
+		shares = averageShares(averages.LastDay)
+	}
+	settlement, err := SplitRevenue(payments, shares, owners, from, to)
+	if err != nil {
+		return nil, err
+	}
+	settlement.Method = method
+	if method == HistoryShares {
+		settlement.Coverage = historyCoverage(histories, from, to)
+	}
+	return &settlement, nil
+}
@@ -0,0 +1,41 @@
+package npapi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSplitRevenue(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	payments := []Payment{
+		{Date: Time(day.Add(time.Hour)), TxHash: "0x1", Amount: 1, Confirmed: true},
+		{Date: Time(day.Add(2 * time.Hour)), TxHash: "0x2", Amount: 1, Confirmed: false},
+		{Date: Time(day.AddDate(0, 0, 1)), TxHash: "0x3", Amount: 1, Confirmed: true},
+	}
+	shares := map[string]float64{"rig1": 0.6, "rig2": 0.2, "rig3": 0.2}
+	owners := Ownership{
+		"rig1": {"alice": 0.5, "bob": 0.5},
+		"rig2": {"bob": 1},
+	}
+	settlement, err := SplitRevenue(payments, shares, owners, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	totals := map[string]float64{"alice": 0.3, "bob": 0.5}
+	if len(settlement.Statements) != len(totals) {
+		t.Fatalf("expected %d statements, got %+v", len(totals), settlement.Statements)
+	}
+	for _, s := range settlement.Statements {
+		if math.Abs(s.Total-totals[s.Owner]) > 1e-9 {
+			t.Errorf("%s: expected %f, got %f", s.Owner, totals[s.Owner], s.Total)
+		}
+	}
+	if math.Abs(settlement.Unallocated-0.2) > 1e-9 {
+		t.Errorf("expected 0.2 unallocated, got %f", settlement.Unallocated)
+	}
+	owners["rig2"]["carol"] = 0.8
+	if _, err := SplitRevenue(payments, shares, owners, day, day.AddDate(0, 0, 1)); err == nil {
+		t.Error("expected an error for stakes exceeding the worker")
+	}
+}
@@ -0,0 +1,218 @@
+package npapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Investment describes the purchase and running costs of a worker.
+type Investment struct {
+	// Worker ID
+	Worker string
+	// Purchase price
+	Price float64
+	// Currency of the purchase and electricity prices
+	Currency Currency
+	// Purchase date
+	Purchased time.Time
+	// Power draw [W]
+	PowerWatts float64
+	// Electricity price per kWh
+	ElectricityPrice float64
+}
+
+// dailyElectricityCost returns the cost of running the worker for a day at the given availability.
+func (i Investment) dailyElectricityCost(availability float64) float64 {
+	return i.PowerWatts * 24 / 1000 * i.ElectricityPrice * availability
+}
+
+// ROIReport stores the return on investment of a worker.
+type ROIReport struct {
+	// Worker ID
+	Worker string
+	// Currency of all values
+	Currency Currency
+	// Coins earned since the purchase
+	Earned float64
+	// Value of the earned coins at the time of each payment
+	EarnedValue float64
+	// Electricity costs since the purchase
+	ElectricityCost float64
+	// Net return since the purchase
+	Net float64
+	// Realized return on investment (Net / Price)
+	ROI float64
+	// Projected net return per day
+	DailyNet float64
+	// Break-even date, projected unless the investment is already recovered, zero if the worker never breaks even
+	BreakEven time.Time
+	// Time from purchase to break-even
+	PaybackPeriod time.Duration
+}
+
+// CalculateROI computes the return on investment of a worker from the parts of the confirmed payments attributed
+// to it. Payments are valued at the price at their date, electricity is charged for the fraction of time the
+// worker is available. If the investment is already recovered, the break-even date is the payment with which the
+// net return first covered the price, otherwise it is projected from the coins the worker is expected to earn per
+// day, valued at the current rates of the converter.
+func CalculateROI(inv Investment, payments []Payment, availability, dailyCoins float64,
+	prices PriceSource, converter *Converter, now time.Time) (ROIReport, error) {
+	report := ROIReport{Worker: inv.Worker, Currency: inv.Currency}
+	payments = append([]Payment(nil), payments...)
+	sort.SliceStable(payments, func(i, j int) bool { return time.Time(payments[i].Date).Before(time.Time(payments[j].Date)) })
+	// electricity costs accrued since the purchase
+	electricity := func(t time.Time) float64 {
+		days := t.Sub(inv.Purchased).Hours() / 24
+		if days < 0 {
+			return 0
+		}
+		return days * inv.dailyElectricityCost(availability)
+	}
+	var recovered time.Time
+	for _, p := range payments {
+		date := time.Time(p.Date)
+		if !p.Confirmed || !date.After(inv.Purchased) || date.After(now) {
+			continue
+		}
+		quote, err := prices.PriceAt(date, inv.Currency)
+		if err != nil {
+			return ROIReport{}, err
+		}
+		report.Earned += p.Amount
+		report.EarnedValue += p.Amount * quote.Price
+		if recovered.IsZero() && report.EarnedValue-electricity(date) >= inv.Price {
+			recovered = date
+		}
+	}
+	report.ElectricityCost = electricity(now)
+	report.Net = report.EarnedValue - report.ElectricityCost
+	if inv.Price > 0 {
+		report.ROI = report.Net / inv.Price
+	}
+	dailyValue, err := converter.Convert(dailyCoins, ETH, inv.Currency)
+	if err != nil {
+		return ROIReport{}, err
+	}
+	report.DailyNet = dailyValue - inv.dailyElectricityCost(availability)
+	remaining := inv.Price - report.Net
+	switch {
+	case remaining <= 0:
+		report.BreakEven = recovered
+	case report.DailyNet > 0:
+		report.BreakEven = now.Add(time.Duration(remaining / report.DailyNet * float64(24*time.Hour)))
+	}
+	if !report.BreakEven.IsZero() {
+		report.PaybackPeriod = report.BreakEven.Sub(inv.Purchased)
+	}
+	return report, nil
+}
+
+// WorkersROI fetches the payments, uptime, average worker hashrates of the last day, prices and approximated
+// earnings of the account and computes the return on investment of each worker, valuing past payments with the
+// price history. Each payment is attributed to the workers by their share counts stored since the previous payment,
+// and the availability since the purchase is derived from the store, see Store.WorkersUptime. The store must have
+// been backfilled regularly since the purchases; the availability of covered periods is assumed for the unknown ones.
+func WorkersROI(store *Store, addr string, investments []Investment, history PriceSource) ([]ROIReport, error) {
+	payments, err := Payments(addr)
+	if err != nil {
+		return nil, err
+	}
+	averages, err := WorkersAverageHashrate(addr)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := Prices()
+	if err != nil {
+		return nil, err
+	}
+	hashrates := WorkerHashrates(averages.LastDay)
+	projection, err := ProjectEarnings(24*time.Hour, hashrates)
+	if err != nil {
+		return nil, err
+	}
+	converter := NewConverter(prices)
+	now := time.Now()
+	reports := make([]ROIReport, len(investments))
+	for i, inv := range investments {
+		uptimes, err := store.WorkersUptime(addr, inv.Purchased, now)
+		if err != nil {
+			return nil, err
+		}
+		availability := -1.0
+		for _, u := range uptimes {
+			if u.Worker == inv.Worker && u.Covered > 0 {
+				availability = u.Availability
+			}
+		}
+		if availability < 0 {
+			return nil, fmt.Errorf("uptime of worker %s since %s is unknown", inv.Worker, inv.Purchased.Format(time.RFC3339))
+		}
+		attributed, err := store.workerPayments(addr, inv.Worker, payments, inv.Purchased)
+		if err != nil {
+			return nil, err
+		}
+		var daily float64
+		for _, p := range projection.Workers {
+			if p.Worker == inv.Worker {
+				daily = p.Coins
+			}
+		}
+		if reports[i], err = CalculateROI(inv, attributed, availability, daily, history, converter, now); err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
+
+// workerPayments attributes the confirmed payments made after since to the worker by its part of the share counts
+// stored for all workers of the account between the previous confirmed payment and the payment. Payments in
+// periods without stored shares are rejected, as their attribution is unknown.
+func (s *Store) workerPayments(addr, worker string, payments []Payment, since time.Time) ([]Payment, error) {
+	ids, err := s.Workers(addr)
+	if err != nil {
+		return nil, err
+	}
+	series := make(map[string][]Point, len(ids))
+	for _, id := range ids {
+		if series[id], err = s.Range(WorkerSharesSeries(addr, id), time.Time{}, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := series[worker]; !ok {
+		return nil, fmt.Errorf("no share history stored for worker %s", worker)
+	}
+	sum := func(points []Point, from, to time.Time) float64 {
+		var total float64
+		for _, p := range points {
+			if p.Date.After(from) && !p.Date.After(to) {
+				total += p.Value
+			}
+		}
+		return total
+	}
+	payments = append([]Payment(nil), payments...)
+	sort.SliceStable(payments, func(i, j int) bool { return time.Time(payments[i].Date).Before(time.Time(payments[j].Date)) })
+	var attributed []Payment
+	var previous time.Time
+	for _, p := range payments {
+		if !p.Confirmed {
+			continue
+		}
+		from, date := previous, time.Time(p.Date)
+		previous = date
+		if !date.After(since) {
+			continue
+		}
+		var total float64
+		for _, points := range series {
+			total += sum(points, from, date)
+		}
+		if total <= 0 {
+			return nil, fmt.Errorf("no shares stored before payment %s at %s", p.TxHash, date.Format(time.RFC3339))
+		}
+		p.Amount *= sum(series[worker], from, date) / total
+		attributed = append(attributed, p)
+	}
+	return attributed, nil
+}
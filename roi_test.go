@@ -0,0 +1,92 @@
+package npapi
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCalculateROI(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	prices := PriceHistory{
+		{Date: day, Prices: PriceReport{USDollar: 200}},
+		{Date: day.AddDate(0, 0, 60), Prices: PriceReport{USDollar: 400}},
+	}
+	var payments []Payment
+	for d := 10; d <= 40; d += 10 {
+		payments = append(payments, Payment{Date: Time(day.AddDate(0, 0, d)), Amount: 0.5, Confirmed: true})
+	}
+	payments = append(payments, Payment{Date: Time(day.AddDate(0, 0, 41)), Amount: 0.5, Confirmed: false})
+	converter := NewConverter(PriceReport{USDollar: 400})
+	inv := Investment{Worker: "rig1", Price: 300, Currency: USD, Purchased: day, PowerWatts: 1000, ElectricityPrice: 0.5}
+	now := day.AddDate(0, 0, 41)
+
+	// payments of 0.5 ETH valued at 233.33, 266.67, 300 and 333.33 USD, electricity at 6 USD per day
+	report, err := CalculateROI(inv, payments, 0.5, 0.05, prices, converter, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Earned != 2 || math.Abs(report.EarnedValue-566.6667) > 1e-3 || math.Abs(report.ElectricityCost-246) > 1e-9 {
+		t.Errorf("unexpected earnings %f worth %f and electricity cost %f", report.Earned, report.EarnedValue, report.ElectricityCost)
+	}
+	// net return was 220 after the third and 326.67 after the fourth payment
+	if !report.BreakEven.Equal(day.AddDate(0, 0, 40)) || report.PaybackPeriod != 40*24*time.Hour {
+		t.Errorf("expected break-even with the fourth payment, got %s", report.BreakEven)
+	}
+
+	inv.Price = 1000
+	if report, err = CalculateROI(inv, payments, 0.5, 0.05, prices, converter, now); err != nil {
+		t.Fatal(err)
+	}
+	// 679.33 USD remaining at a daily net return of 20 - 6 USD
+	remaining := 1000 - report.Net
+	want := now.Add(time.Duration(remaining / 14 * float64(24*time.Hour)))
+	if report.DailyNet != 14 || math.Abs(report.Net-320.6667) > 1e-3 || math.Abs(report.BreakEven.Sub(want).Hours()) > 0.1 {
+		t.Errorf("expected a projected break-even at %s, got %s with a daily net return of %f", want, report.BreakEven, report.DailyNet)
+	}
+}
+
+func TestStoreWorkerPayments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "npapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	// rig1 mines alone on the first day and alongside rig2 with a third of the shares on the second
+	rig1 := []Point{{Date: day.Add(time.Hour), Value: 30}, {Date: day.Add(25 * time.Hour), Value: 10}}
+	if err := store.Append(WorkerSharesSeries("0x0", "rig1"), rig1...); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(WorkerSharesSeries("0x0", "rig2"), Point{Date: day.Add(26 * time.Hour), Value: 20}); err != nil {
+		t.Fatal(err)
+	}
+	payments := []Payment{
+		{Date: Time(day.AddDate(0, 0, 2)), TxHash: "0x2", Amount: 3, Confirmed: true},
+		{Date: Time(day.AddDate(0, 0, 1)), TxHash: "0x1", Amount: 2, Confirmed: true},
+		{Date: Time(day.Add(36 * time.Hour)), TxHash: "0x3", Amount: 1, Confirmed: false},
+	}
+	attributed, err := store.workerPayments("0x0", "rig1", payments, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attributed) != 2 || attributed[0].Amount != 2 || attributed[1].Amount != 1 {
+		t.Errorf("expected 2 and 1 ETH attributed to rig1, got %+v", attributed)
+	}
+	if attributed, err = store.workerPayments("0x0", "rig2", payments, day.Add(time.Hour)); err != nil || len(attributed) != 2 || attributed[0].Amount != 0 {
+		t.Errorf("expected nothing of the first payment attributed to rig2, got %+v, %v", attributed, err)
+	}
+	payments = append(payments, Payment{Date: Time(day.AddDate(0, 0, 3)), TxHash: "0x4", Amount: 1, Confirmed: true})
+	if _, err := store.workerPayments("0x0", "rig1", payments, day); err == nil {
+		t.Error("expected an error for a payment without stored shares")
+	}
+	if _, err := store.workerPayments("0x0", "rig3", payments[:2], day); err == nil {
+		t.Error("expected an error for a worker without stored shares")
+	}
+}
@@ -0,0 +1,168 @@
+package npapi
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Aggregator reduces the values of a bucket to a single value.
+type Aggregator func(values []float64) float64
+
+// Mean returns the arithmetic mean of the values.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return Sum(values) / float64(len(values))
+}
+
+// Sum returns the sum of the values.
+func Sum(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+// Max returns the largest value.
+func Max(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	max := values[0]
+	for _, v := range values[1:] {
+		max = math.Max(max, v)
+	}
+	return max
+}
+
+// Min returns the smallest value.
+func Min(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	min := values[0]
+	for _, v := range values[1:] {
+		min = math.Min(min, v)
+	}
+	return min
+}
+
+// Percentile creates an aggregator returning the p-th percentile (0 <= p <= 1) of the values.
+func Percentile(p float64) Aggregator {
+	return func(values []float64) float64 {
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+		return percentile(sorted, p)
+	}
+}
+
+// HistoryPoints converts history items to points.
+func HistoryPoints(history []HistoryItem) []Point {
+	points := make([]Point, len(history))
+	for i, h := range history {
+		points[i] = Point{Date: time.Time(h.Date), Value: h.Hashrate}
+	}
+	return points
+}
+
+// SharePoints converts share items to points.
+func SharePoints(shares []ShareItem) []Point {
+	points := make([]Point, len(shares))
+	for i, s := range shares {
+		points[i] = Point{Date: time.Time(s.Date), Value: float64(s.Shares)}
+	}
+	return points
+}
+
+// ChartHashratePoints converts the hashrates of chart items to points.
+func ChartHashratePoints(chart []ChartItem) []Point {
+	points := make([]Point, len(chart))
+	for i, c := range chart {
+		points[i] = Point{Date: time.Time(c.Date), Value: c.Hashrate}
+	}
+	return points
+}
+
+// ChartSharePoints converts the share counts of chart items to points.
+func ChartSharePoints(chart []ChartItem) []Point {
+	points := make([]Point, len(chart))
+	for i, c := range chart {
+		points[i] = Point{Date: time.Time(c.Date), Value: float64(c.Shares)}
+	}
+	return points
+}
+
+// BucketStart returns the start of the bucket t belongs to. Buckets are aligned to midnight in the given
+// location; buckets of whole days follow calendar days, so that they stay aligned across DST changes.
+func BucketStart(t time.Time, bucket time.Duration, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	day := startOfDay(t, loc)
+	const fullDay = 24 * time.Hour
+	if bucket >= fullDay && bucket%fullDay == 0 {
+		days := int(bucket / fullDay)
+		// count days from a fixed reference to keep multi-day buckets stable
+		reference := time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
+		n := int(math.Floor(day.Sub(reference).Hours()/24 + 0.5))
+		return time.Date(1970, 1, 1+n-n%days, 0, 0, 0, 0, loc)
+	}
+	return day.Add(t.Sub(day) / bucket * bucket)
+}
+
+// Resample groups the points into buckets of the given size aligned in the given location and aggregates each bucket.
+func Resample(points []Point, bucket time.Duration, agg Aggregator, loc *time.Location) []Point {
+	if bucket <= 0 {
+		return points
+	}
+	groups := make(map[int64][]float64)
+	starts := make(map[int64]time.Time)
+	for _, p := range points {
+		start := BucketStart(p.Date, bucket, loc)
+		groups[start.Unix()] = append(groups[start.Unix()], p.Value)
+		starts[start.Unix()] = start
+	}
+	result := make([]Point, 0, len(groups))
+	for key, values := range groups {
+		result = append(result, Point{Date: starts[key], Value: agg(values)})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result
+}
+
+// MovingAverage computes the trailing mean over the last n points.
+func MovingAverage(points []Point, n int) []Point {
+	if n <= 0 {
+		n = 1
+	}
+	result := make([]Point, len(points))
+	var sum float64
+	for i, p := range points {
+		sum += p.Value
+		if i >= n {
+			sum -= points[i-n].Value
+		}
+		count := n
+		if i < n {
+			count = i + 1
+		}
+		result[i] = Point{Date: p.Date, Value: sum / float64(count)}
+	}
+	return result
+}
+
+// EWMA computes the exponentially weighted moving average with the smoothing factor alpha (0 < alpha <= 1).
+func EWMA(points []Point, alpha float64) []Point {
+	result := make([]Point, len(points))
+	for i, p := range points {
+		if i == 0 {
+			result[i] = p
+			continue
+		}
+		result[i] = Point{Date: p.Date, Value: alpha*p.Value + (1-alpha)*result[i-1].Value}
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package npapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResampleDaily(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	start := time.Date(2017, 6, 1, 20, 0, 0, 0, time.UTC)
+	var points []Point
+	for i := 0; i < 6; i++ {
+		points = append(points, Point{Date: start.Add(time.Duration(i) * time.Hour), Value: float64(i)})
+	}
+	daily := Resample(points, 24*time.Hour, Sum, loc)
+	if len(daily) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(daily))
+	}
+	if want := time.Date(2017, 6, 1, 0, 0, 0, 0, loc); !daily[0].Date.Equal(want) || daily[0].Value != 1 {
+		t.Errorf("unexpected first bucket %v", daily[0])
+	}
+	if want := time.Date(2017, 6, 2, 0, 0, 0, 0, loc); !daily[1].Date.Equal(want) || daily[1].Value != 14 {
+		t.Errorf("unexpected second bucket %v", daily[1])
+	}
+}
+
+func TestResampleHourly(t *testing.T) {
+	start := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	var points []Point
+	for i := 0; i < 12; i++ {
+		points = append(points, Point{Date: start.Add(time.Duration(i) * 10 * time.Minute), Value: float64(i)})
+	}
+	hourly := Resample(points, time.Hour, Max, time.UTC)
+	if len(hourly) != 2 || hourly[0].Value != 5 || hourly[1].Value != 11 {
+		t.Errorf("unexpected hourly buckets %v", hourly)
+	}
+	if p := Percentile(0.5)([]float64{4, 1, 3, 2}); p != 2.5 {
+		t.Errorf("expected median 2.5, got %f", p)
+	}
+}
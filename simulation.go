@@ -0,0 +1,177 @@
+package npapi
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DefaultBlockReward is the static Ethereum block reward in ETH.
+const DefaultBlockReward = 2.0
+
+// SimulationConfig describes the account, pool and network modelled by Simulate.
+type SimulationConfig struct {
+	// Account hashrate [MH/s]
+	Hashrate float64
+	// Pool hashrate [MH/s]
+	PoolHashrate float64
+	// Network difficulty
+	Difficulty float64
+	// Block reward in ETH
+	BlockReward float64
+	// Pool fee as a fraction of the block reward
+	Fee float64
+	// Number of shares in the PPLNS window
+	WindowShares float64
+	// Payout threshold in ETH, zero counts as reached immediately
+	Threshold float64
+	// Days within which the payout threshold should be reached, 7 if zero
+	HorizonDays int
+	// Number of Monte Carlo trials
+	Trials int
+	// Random seed
+	Seed int64
+}
+
+// Distribution summarizes simulated earnings in ETH.
+type Distribution struct {
+	Mean, StdDev float64
+	// 5th, 50th and 95th percentile
+	P5, P50, P95 float64
+	// Probability of earning nothing
+	Zero float64
+}
+
+// SimulationResult stores the simulated earnings distributions.
+type SimulationResult struct {
+	// Earnings per day and per week
+	Daily, Weekly Distribution
+	// Probability of reaching the payout threshold within the horizon
+	PayoutProbability float64
+}
+
+// poissonSample draws from a Poisson distribution, using a normal approximation for large means.
+func poissonSample(r *rand.Rand, lambda float64) float64 {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 30 {
+		return math.Max(0, math.Floor(r.NormFloat64()*math.Sqrt(lambda)+lambda+0.5))
+	}
+	limit, product, k := math.Exp(-lambda), r.Float64(), 0.0
+	for product > limit {
+		product *= r.Float64()
+		k++
+	}
+	return k
+}
+
+// distribution summarizes the samples.
+func distribution(samples []float64) Distribution {
+	if len(samples) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	d := Distribution{
+		Mean: Mean(sorted),
+		P5:   percentile(sorted, 0.05),
+		P50:  percentile(sorted, 0.5),
+		P95:  percentile(sorted, 0.95),
+	}
+	var squares, zero float64
+	for _, s := range sorted {
+		squares += (s - d.Mean) * (s - d.Mean)
+		if s == 0 {
+			zero++
+		}
+	}
+	d.StdDev = math.Sqrt(squares / float64(len(sorted)))
+	d.Zero = zero / float64(len(sorted))
+	return d
+}
+
+// Simulate runs Monte Carlo trials of PPLNS payouts. Pool blocks arrive as a Poisson process; for each block the
+// account's shares in the PPLNS window are drawn from a Poisson distribution proportional to its hashrate share.
+// Overlap between windows of consecutive blocks is ignored, which slightly underestimates variance.
+// Each trial covers at least a week for the earnings distributions, while the payout probability only
+// considers the first HorizonDays.
+func Simulate(config SimulationConfig) SimulationResult {
+	var result SimulationResult
+	if config.Difficulty <= 0 || config.PoolHashrate <= 0 || config.WindowShares <= 0 {
+		return result
+	}
+	trials := config.Trials
+	if trials <= 0 {
+		trials = 10000
+	}
+	horizon := config.HorizonDays
+	if horizon <= 0 {
+		horizon = 7
+	}
+	days := horizon
+	if days < 7 {
+		days = 7
+	}
+	r := rand.New(rand.NewSource(config.Seed))
+	blocksPerDay := config.PoolHashrate * megahash * 86400 / config.Difficulty
+	expectedShares := config.WindowShares * config.Hashrate / config.PoolHashrate
+	rewardPerShare := config.BlockReward * (1 - config.Fee) / config.WindowShares
+	daily := make([]float64, 0, trials*7)
+	weekly := make([]float64, 0, trials)
+	var reached int
+	for t := 0; t < trials; t++ {
+		var total, week float64
+		paid := config.Threshold <= 0
+		for d := 0; d < days; d++ {
+			var day float64
+			blocks := int(poissonSample(r, blocksPerDay))
+			for b := 0; b < blocks; b++ {
+				day += poissonSample(r, expectedShares) * rewardPerShare
+			}
+			total += day
+			if d < 7 {
+				daily = append(daily, day)
+				week += day
+			}
+			if d < horizon && total >= config.Threshold {
+				paid = true
+			}
+		}
+		weekly = append(weekly, week)
+		if paid {
+			reached++
+		}
+	}
+	result.Daily = distribution(daily)
+	result.Weekly = distribution(weekly)
+	result.PayoutProbability = float64(reached) / float64(trials)
+	return result
+}
+
+// NewSimulationConfig fetches the account hashrate of the last day, the pool hashrate and the latest network
+// difficulty and fills a configuration using the default block reward. The PPLNS window, fee and payout
+// threshold must be set by the caller.
+func NewSimulationConfig(addr string) (SimulationConfig, error) {
+	hashrates, err := AverageHashrate(addr)
+	if err != nil {
+		return SimulationConfig{}, err
+	}
+	pool, err := PoolHashrate()
+	if err != nil {
+		return SimulationConfig{}, err
+	}
+	stats, err := BlockStats(0, 100)
+	if err != nil {
+		return SimulationConfig{}, err
+	}
+	network := AnalyzeNetworkTrend(stats, len(stats))
+	return SimulationConfig{
+		Hashrate:     hashrates.LastDay,
+		PoolHashrate: pool,
+		Difficulty:   network.Difficulty,
+		BlockReward:  DefaultBlockReward,
+		HorizonDays:  7,
+		Trials:       10000,
+	}, nil
+}
@@ -0,0 +1,37 @@
+package npapi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimulateMean(t *testing.T) {
+	config := SimulationConfig{
+		Hashrate:     200,
+		PoolHashrate: 2e6,
+		Difficulty:   2e15,
+		BlockReward:  DefaultBlockReward,
+		Fee:          0.01,
+		WindowShares: 1e5,
+		Threshold:    0.05,
+		HorizonDays:  3,
+		Trials:       5000,
+		Seed:         1,
+	}
+	result := Simulate(config)
+	blocksPerDay := config.PoolHashrate * megahash * 86400 / config.Difficulty
+	expected := blocksPerDay * config.Hashrate / config.PoolHashrate * config.BlockReward * (1 - config.Fee)
+	if math.Abs(result.Daily.Mean-expected)/expected > 0.02 {
+		t.Errorf("expected a daily mean of %f, got %f", expected, result.Daily.Mean)
+	}
+	if math.Abs(result.Weekly.Mean-7*expected)/expected > 0.1 {
+		t.Errorf("expected a weekly mean of %f, got %f", 7*expected, result.Weekly.Mean)
+	}
+	if result.PayoutProbability <= 0 || result.PayoutProbability >= 1 {
+		t.Errorf("expected a payout probability between 0 and 1, got %f", result.PayoutProbability)
+	}
+	config.Threshold = 0
+	if p := Simulate(config).PayoutProbability; p != 1 {
+		t.Errorf("expected a payout probability of 1 without threshold, got %f", p)
+	}
+}
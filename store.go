@@ -0,0 +1,416 @@
+package npapi
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Series names of pool-wide metrics recorded by Store.Poll.
+const (
+	PoolHashrateSeries = "pool/hashrate"
+	PoolMinersSeries   = "pool/miners"
+	PoolWorkersSeries  = "pool/workers"
+)
+
+// HashrateSeries returns the series name of the account hashrate.
+func HashrateSeries(addr string) string { return "hashrate/" + addr }
+
+// BalanceSeries returns the series name of the account balance.
+func BalanceSeries(addr string) string { return "balance/" + addr }
+
+// SharesSeries returns the series name of the account share counts.
+func SharesSeries(addr string) string { return "shares/" + addr }
+
+// WorkerSharesSeries returns the series name of the worker share counts.
+func WorkerSharesSeries(addr, worker string) string { return SharesSeries(addr + "/" + worker) }
+
+// PriceSeries returns the series name of the ETH price in the given currency.
+func PriceSeries(currency Currency) string { return "price/" + strings.ToLower(string(currency)) }
+
+// Point is a single value of a time series.
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+// Downsample merges points older than After into buckets of the given resolution, reducing their values
+// with the aggregator of the retention policy.
+type Downsample struct {
+	After      time.Duration
+	Resolution time.Duration
+}
+
+// Retention controls how long points are kept and at which resolution.
+type Retention struct {
+	// Points older than MaxAge are dropped, zero keeps all points
+	MaxAge time.Duration
+	// Downsampling rules, applied in order
+	Downsample []Downsample
+	// Aggregator reducing downsampled buckets, Mean if nil
+	Aggregate Aggregator
+}
+
+// DefaultRetention keeps a year of points, reducing them to hourly resolution after a week and daily resolution after three months.
+var DefaultRetention = Retention{
+	MaxAge: 365 * 24 * time.Hour,
+	Downsample: []Downsample{
+		{After: 7 * 24 * time.Hour, Resolution: time.Hour},
+		{After: 90 * 24 * time.Hour, Resolution: 24 * time.Hour},
+	},
+}
+
+// ShareRetention keeps a year of share counts. Counts stay at their 10-minute resolution for four months,
+// so that uptime can be derived for whole months and quarters, and are summed to daily counts after that.
+var ShareRetention = Retention{
+	MaxAge: 365 * 24 * time.Hour,
+	Downsample: []Downsample{
+		{After: 120 * 24 * time.Hour, Resolution: 24 * time.Hour},
+	},
+	Aggregate: Sum,
+}
+
+// Store is an embedded, file-backed time-series store. Each series is kept as a CSV file of
+// unix timestamps and values inside the store directory.
+type Store struct {
+	// Store directory
+	Dir string
+	// Retention policy applied by Compact
+	Retention Retention
+	// Retention policies of the series starting with a prefix, overriding Retention
+	Retentions map[string]Retention
+
+	mu sync.Mutex
+}
+
+// OpenStore opens the store in the given directory, creating it if necessary.
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{
+		Dir:        dir,
+		Retention:  DefaultRetention,
+		Retentions: map[string]Retention{"shares/": ShareRetention},
+	}, nil
+}
+
+func (s *Store) path(series string) string {
+	return filepath.Join(s.Dir, url.PathEscape(series)+".csv")
+}
+
+// Append adds points to the series.
+func (s *Store) Append(series string, points ...Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(series, points)
+}
+
+// write appends points to the series file. The caller must hold the store lock.
+func (s *Store) write(series string, points []Point) error {
+	file, err := os.OpenFile(s.path(series), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	for _, p := range points {
+		fmt.Fprintf(writer, "%d,%s\n", p.Date.Unix(), strconv.FormatFloat(p.Value, 'g', -1, 64))
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// read loads all points of the series, ordered by date. Points sharing a date are deduplicated, keeping the last one written.
+func (s *Store) read(series string) ([]Point, error) {
+	file, err := os.Open(s.path(series))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var points []Point
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ",", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		secs, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, Point{Date: time.Unix(secs, 0), Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	deduped := points[:0]
+	for _, p := range points {
+		if n := len(deduped); n > 0 && deduped[n-1].Date.Equal(p.Date) {
+			deduped[n-1] = p
+			continue
+		}
+		deduped = append(deduped, p)
+	}
+	return deduped, nil
+}
+
+// Range returns the points of the series in the interval [from, to], ordered by date.
+func (s *Store) Range(series string, from, to time.Time) ([]Point, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	points, err := s.read(series)
+	if err != nil {
+		return nil, err
+	}
+	var result []Point
+	for _, p := range points {
+		if !p.Date.Before(from) && !p.Date.After(to) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// Series lists the names of all series in the store.
+func (s *Store) Series() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.Dir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		name, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(f), ".csv"))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Workers lists the IDs of the workers of the account with a stored share history.
+func (s *Store) Workers(addr string) ([]string, error) {
+	names, err := s.Series()
+	if err != nil {
+		return nil, err
+	}
+	prefix := WorkerSharesSeries(addr, "")
+	var ids []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			ids = append(ids, strings.TrimPrefix(name, prefix))
+		}
+	}
+	return ids, nil
+}
+
+// retention returns the retention policy of the series, preferring the longest matching prefix.
+func (s *Store) retention(series string) Retention {
+	policy, longest := s.Retention, -1
+	for prefix, r := range s.Retentions {
+		if strings.HasPrefix(series, prefix) && len(prefix) > longest {
+			policy, longest = r, len(prefix)
+		}
+	}
+	return policy
+}
+
+// applyRetention drops expired points and downsamples old points according to the policy.
+func applyRetention(points []Point, policy Retention, now time.Time) []Point {
+	var kept []Point
+	for _, p := range points {
+		if policy.MaxAge > 0 && now.Sub(p.Date) > policy.MaxAge {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	aggregate := policy.Aggregate
+	if aggregate == nil {
+		aggregate = Mean
+	}
+	for _, rule := range policy.Downsample {
+		if rule.Resolution <= 0 {
+			continue
+		}
+		cutoff := now.Add(-rule.After)
+		var result []Point
+		var values []float64
+		var bucket time.Time
+		flush := func() {
+			if len(values) > 0 {
+				result = append(result, Point{Date: bucket, Value: aggregate(values)})
+			}
+			values = nil
+		}
+		for _, p := range kept {
+			if !p.Date.Before(cutoff) {
+				flush()
+				result = append(result, p)
+				continue
+			}
+			if start := p.Date.Truncate(rule.Resolution); len(values) == 0 || !start.Equal(bucket) {
+				flush()
+				bucket = start
+			}
+			values = append(values, p.Value)
+		}
+		flush()
+		kept = result
+	}
+	return kept
+}
+
+// Compact rewrites every series, removing duplicates and applying its retention policy.
+func (s *Store) Compact(now time.Time) error {
+	names, err := s.Series()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		points, err := s.read(name)
+		if err != nil {
+			return err
+		}
+		points = applyRetention(points, s.retention(name), now)
+		tmp := s.path(name) + ".tmp"
+		file, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		writer := bufio.NewWriter(file)
+		for _, p := range points {
+			fmt.Fprintf(writer, "%d,%s\n", p.Date.Unix(), strconv.FormatFloat(p.Value, 'g', -1, 64))
+		}
+		if err := writer.Flush(); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, s.path(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HashrateHistory returns the points of a hashrate series in the interval [from, to] as history items.
+func (s *Store) HashrateHistory(series string, from, to time.Time) ([]HistoryItem, error) {
+	points, err := s.Range(series, from, to)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]HistoryItem, len(points))
+	for i, p := range points {
+		history[i] = HistoryItem{Date: Time(p.Date), Hashrate: p.Value}
+	}
+	return history, nil
+}
+
+// ShareHistory returns the points of a share series in the interval [from, to] as share items. Items of
+// downsampled buckets hold the share count of the whole bucket.
+func (s *Store) ShareHistory(series string, from, to time.Time) ([]ShareItem, error) {
+	points, err := s.Range(series, from, to)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]ShareItem, len(points))
+	for i, p := range points {
+		history[i] = ShareItem{Date: Time(p.Date), Shares: uint(p.Value + 0.5)}
+	}
+	return history, nil
+}
+
+// HashrateChart joins a hashrate and a share series in the interval [from, to] into chart items.
+// Only dates present in the hashrate series are included.
+func (s *Store) HashrateChart(hashrateSeries, sharesSeries string, from, to time.Time) ([]ChartItem, error) {
+	hashrates, err := s.Range(hashrateSeries, from, to)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := s.Range(sharesSeries, from, to)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]float64, len(shares))
+	for _, p := range shares {
+		counts[p.Date.Unix()] = p.Value
+	}
+	chart := make([]ChartItem, len(hashrates))
+	for i, p := range hashrates {
+		chart[i] = ChartItem{Date: Time(p.Date), Shares: uint(counts[p.Date.Unix()] + 0.5), Hashrate: p.Value}
+	}
+	return chart, nil
+}
+
+// Poll fetches the hashrate, balance and share history of the account, the current prices and the
+// pool stats and records them in the store. If addr is empty, only prices and pool stats are recorded.
+func (s *Store) Poll(addr string) error {
+	now := time.Now()
+	if addr != "" {
+		hashrate, balance, err := HashrateAndBalance(addr)
+		if err != nil {
+			return err
+		}
+		if err := s.Append(HashrateSeries(addr), Point{now, hashrate}); err != nil {
+			return err
+		}
+		if err := s.Append(BalanceSeries(addr), Point{now, balance}); err != nil {
+			return err
+		}
+		// the share history window overlaps with the previous poll, only merge new buckets
+		_, err = s.Backfill(SharesSeries(addr), now, KeepMax, func() ([]Point, error) {
+			shares, err := ShareHistory(addr)
+			return SharePoints(shares), err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	prices, err := Prices()
+	if err != nil {
+		return err
+	}
+	for _, currency := range PriceCurrencies {
+		price, err := prices.In(currency)
+		if err != nil {
+			return err
+		}
+		if err := s.Append(PriceSeries(currency), Point{now, price}); err != nil {
+			return err
+		}
+	}
+	overview, err := PoolOverview("")
+	if err != nil {
+		return err
+	}
+	if err := s.Append(PoolHashrateSeries, Point{now, overview.Hashrate}); err != nil {
+		return err
+	}
+	if err := s.Append(PoolMinersSeries, Point{now, float64(overview.Miners)}); err != nil {
+		return err
+	}
+	return s.Append(PoolWorkersSeries, Point{now, float64(overview.Workers)})
+}
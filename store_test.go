@@ -0,0 +1,106 @@
+package npapi
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func countLines(t *testing.T, path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines
+}
+
+func TestStoreBackfill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "npapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1496275200, 0)
+	window := func(offset int) func() ([]Point, error) {
+		return func() ([]Point, error) {
+			points := make([]Point, 6)
+			for i := range points {
+				points[i] = Point{Date: start.Add(time.Duration(offset+i) * ShareInterval), Value: float64(offset + i)}
+			}
+			return points, nil
+		}
+	}
+	series := SharesSeries("0x0")
+	for _, offset := range []int{0, 0, 3} {
+		if _, err := store.Backfill(series, start, KeepMax, window(offset)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines := countLines(t, store.path(series)); lines != 9 {
+		t.Errorf("expected 9 stored points, got %d", lines)
+	}
+	points, err := store.Range(series, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range points {
+		if p.Value != float64(i) {
+			t.Errorf("point %d: expected %d, got %f", i, i, p.Value)
+		}
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "npapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Retention = Retention{MaxAge: 48 * time.Hour, Downsample: []Downsample{{After: 24 * time.Hour, Resolution: time.Hour}}}
+	store.Retentions["shares/"] = Retention{Downsample: []Downsample{{After: 24 * time.Hour, Resolution: time.Hour}}, Aggregate: Sum}
+	now := time.Unix(1496275200, 0)
+	var points []Point
+	for d := 72 * time.Hour; d > 0; d -= ShareInterval {
+		points = append(points, Point{Date: now.Add(-d), Value: 2})
+	}
+	if err := store.Append(HashrateSeries("0x0"), points...); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(SharesSeries("0x0"), points...); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Compact(now); err != nil {
+		t.Fatal(err)
+	}
+	hashrates, err := store.Range(HashrateSeries("0x0"), time.Time{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 24 hourly buckets followed by 144 points at full resolution, older points expired
+	if len(hashrates) != 24+144 || hashrates[0].Value != 2 || !hashrates[0].Date.Equal(now.Add(-48*time.Hour)) {
+		t.Errorf("unexpected hashrate series of %d points starting with %+v", len(hashrates), hashrates[0])
+	}
+	shares, err := store.Range(SharesSeries("0x0"), time.Time{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 48+144 || shares[0].Value != 12 || shares[len(shares)-1].Value != 2 {
+		t.Errorf("expected summed hourly share counts, got %d points starting with %+v", len(shares), shares[0])
+	}
+}
@@ -0,0 +1,292 @@
+package npapi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CostBasisMethod selects the order in which lots are consumed by disposals.
+type CostBasisMethod int
+
+const (
+	// FIFO consumes the oldest lots first
+	FIFO CostBasisMethod = iota
+	// LIFO consumes the newest lots first
+	LIFO
+)
+
+// TaxFormat selects the CSV layout written by TaxReport.WriteCSV.
+type TaxFormat int
+
+const (
+	// GenericTaxFormat lists date, transaction, amount, price and value
+	GenericTaxFormat TaxFormat = iota
+	// KoinlyTaxFormat is the Koinly universal import format
+	KoinlyTaxFormat
+	// CoinTrackingTaxFormat is the CoinTracking CSV import format
+	CoinTrackingTaxFormat
+)
+
+// PriceQuote is an ETH price looked up for a point in time.
+type PriceQuote struct {
+	// ETH price in the requested currency
+	Price float64
+	// Distance to the nearest known price
+	Staleness time.Duration
+	// Interpolated is true if the price lies between two known prices
+	Interpolated bool
+}
+
+// PriceSource looks up historical ETH prices, e.g. from a PriceHistory imported from CSV.
+type PriceSource interface {
+	// PriceAt returns the ETH price in the currency at t.
+	PriceAt(t time.Time, c Currency) (PriceQuote, error)
+}
+
+// ValuedPayment is a payment valued at the ETH price at the time of payment.
+type ValuedPayment struct {
+	Payment
+	// ETH price at the time of payment
+	Price float64
+	// Distance between the payment and the nearest known price
+	Staleness time.Duration
+	// Payment value (Amount * Price)
+	Value float64
+}
+
+// Lot is an amount of ETH acquired by a single payment.
+type Lot struct {
+	// Acquisition date
+	Acquired time.Time
+	// Payment transaction hash
+	TxHash string
+	// Acquired amount
+	Amount float64
+	// Amount not yet disposed of
+	Remaining float64
+	// Cost basis per ETH
+	Price float64
+}
+
+// Disposal is a sale of ETH received from the pool.
+type Disposal struct {
+	// Sale date
+	Date time.Time
+	// Sold amount
+	Amount float64
+	// Sale proceeds
+	Proceeds float64
+}
+
+// RealizedGain is the part of a disposal matched against a single lot.
+type RealizedGain struct {
+	// Acquisition date of the lot
+	Acquired time.Time
+	// Sale date
+	Disposed time.Time
+	// Matched amount
+	Amount float64
+	// Cost basis of the matched amount
+	Cost float64
+	// Proceeds of the matched amount
+	Proceeds float64
+	// Gain (Proceeds - Cost)
+	Gain float64
+}
+
+// YearTotal sums the mining income and realized gains of a calendar year.
+type YearTotal struct {
+	Year int
+	// Received coins
+	Coins float64
+	// Mining income
+	Income float64
+	// Realized gains
+	Gains float64
+}
+
+// TaxReport values payments in a fiat currency and derives cost-basis lots and yearly totals.
+type TaxReport struct {
+	// Currency of all values
+	Currency Currency
+	// Valued payments, ordered by date
+	Payments []ValuedPayment
+	// Lots after applying all disposals
+	Lots []Lot
+	// Gains realized by disposals
+	Gains []RealizedGain
+	// Totals per calendar year
+	Years []YearTotal
+}
+
+// ValuePayments values each confirmed payment at the ETH price at its timestamp. An error is returned if the
+// nearest known price is further than maxStaleness away from a payment; zero disables the check.
+func ValuePayments(payments []Payment, prices PriceSource, currency Currency, maxStaleness time.Duration) ([]ValuedPayment, error) {
+	var valued []ValuedPayment
+	for _, p := range payments {
+		if !p.Confirmed {
+			continue
+		}
+		date := time.Time(p.Date)
+		quote, err := prices.PriceAt(date, currency)
+		if err != nil {
+			return nil, err
+		}
+		if maxStaleness > 0 && quote.Staleness > maxStaleness {
+			return nil, fmt.Errorf("no %s price known within %s of payment %s at %s", currency, maxStaleness, p.TxHash, date.Format(time.RFC3339))
+		}
+		valued = append(valued, ValuedPayment{
+			Payment:   p,
+			Price:     quote.Price,
+			Staleness: quote.Staleness,
+			Value:     p.Amount * quote.Price,
+		})
+	}
+	sort.SliceStable(valued, func(i, j int) bool {
+		return time.Time(valued[i].Date).Before(time.Time(valued[j].Date))
+	})
+	return valued, nil
+}
+
+// NewTaxReport creates a tax report from the payments and disposals, valuing everything in the given currency.
+// Payments without a price known within maxStaleness are rejected, see ValuePayments. Year totals
+// use calendar years in loc; a nil location selects the local time zone.
+func NewTaxReport(payments []Payment, prices PriceSource, currency Currency, maxStaleness time.Duration,
+	method CostBasisMethod, disposals []Disposal, loc *time.Location) (*TaxReport, error) {
+	valued, err := ValuePayments(payments, prices, currency, maxStaleness)
+	if err != nil {
+		return nil, err
+	}
+	report := &TaxReport{Currency: currency, Payments: valued}
+	if loc == nil {
+		loc = time.Local
+	}
+	years := make(map[int]*YearTotal)
+	yearOf := func(t time.Time) *YearTotal {
+		year := t.In(loc).Year()
+		if y, ok := years[year]; ok {
+			return y
+		}
+		years[year] = &YearTotal{Year: year}
+		return years[year]
+	}
+	for _, p := range valued {
+		date := time.Time(p.Date)
+		report.Lots = append(report.Lots, Lot{
+			Acquired:  date,
+			TxHash:    p.TxHash,
+			Amount:    p.Amount,
+			Remaining: p.Amount,
+			Price:     p.Price,
+		})
+		y := yearOf(date)
+		y.Coins += p.Amount
+		y.Income += p.Value
+	}
+	disposals = append([]Disposal(nil), disposals...)
+	sort.SliceStable(disposals, func(i, j int) bool { return disposals[i].Date.Before(disposals[j].Date) })
+	for _, d := range disposals {
+		gains, err := report.dispose(d, method)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range gains {
+			yearOf(d.Date).Gains += g.Gain
+		}
+		report.Gains = append(report.Gains, gains...)
+	}
+	for _, y := range years {
+		report.Years = append(report.Years, *y)
+	}
+	sort.Slice(report.Years, func(i, j int) bool { return report.Years[i].Year < report.Years[j].Year })
+	return report, nil
+}
+
+// dispose matches a disposal against the lots available at the disposal date.
+func (r *TaxReport) dispose(d Disposal, method CostBasisMethod) ([]RealizedGain, error) {
+	var available []int
+	for i, l := range r.Lots {
+		if !l.Acquired.After(d.Date) && l.Remaining > 0 {
+			available = append(available, i)
+		}
+	}
+	if method == LIFO {
+		for i, j := 0, len(available)-1; i < j; i, j = i+1, j-1 {
+			available[i], available[j] = available[j], available[i]
+		}
+	}
+	var gains []RealizedGain
+	remaining := d.Amount
+	for _, i := range available {
+		if remaining <= 0 {
+			break
+		}
+		lot := &r.Lots[i]
+		amount := lot.Remaining
+		if amount > remaining {
+			amount = remaining
+		}
+		lot.Remaining -= amount
+		remaining -= amount
+		proceeds := d.Proceeds * amount / d.Amount
+		cost := lot.Price * amount
+		gains = append(gains, RealizedGain{
+			Acquired: lot.Acquired,
+			Disposed: d.Date,
+			Amount:   amount,
+			Cost:     cost,
+			Proceeds: proceeds,
+			Gain:     proceeds - cost,
+		})
+	}
+	if remaining > 1e-12 {
+		return nil, fmt.Errorf("disposal of %f ETH at %s exceeds available lots", d.Amount, d.Date.Format(time.RFC3339))
+	}
+	return gains, nil
+}
+
+// WriteCSV writes the valued payments in the given format.
+func (r *TaxReport) WriteCSV(w io.Writer, format TaxFormat) error {
+	writer := csv.NewWriter(w)
+	amount := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	value := func(f float64) string { return strconv.FormatFloat(f, 'f', 2, 64) }
+	var header []string
+	switch format {
+	case GenericTaxFormat:
+		header = []string{"date", "txhash", "amount", "price", "value", "currency"}
+	case KoinlyTaxFormat:
+		header = []string{"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+			"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash"}
+	case CoinTrackingTaxFormat:
+		header = []string{"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency",
+			"Fee", "Fee Currency", "Exchange", "Trade-Group", "Comment", "Date"}
+	default:
+		return fmt.Errorf("unknown tax format %d", format)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, p := range r.Payments {
+		date := time.Time(p.Date).UTC()
+		var record []string
+		switch format {
+		case GenericTaxFormat:
+			record = []string{date.Format(time.RFC3339), p.TxHash, amount(p.Amount), amount(p.Price), value(p.Value), string(r.Currency)}
+		case KoinlyTaxFormat:
+			record = []string{date.Format("2006-01-02 15:04:05 UTC"), "", "", amount(p.Amount), "ETH",
+				"", "", value(p.Value), string(r.Currency), "mining", "Nanopool payout", p.TxHash}
+		case CoinTrackingTaxFormat:
+			record = []string{"Mining", amount(p.Amount), "ETH", "", "",
+				"", "", "Nanopool", "", p.TxHash, date.Format("02.01.2006 15:04:05")}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,96 @@
+package npapi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTaxReportCostBasis(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	prices := PriceHistory{
+		{Date: day, Prices: PriceReport{USDollar: 100}},
+		{Date: day.AddDate(0, 0, 2), Prices: PriceReport{USDollar: 300}},
+	}
+	payments := []Payment{
+		{Date: Time(day), TxHash: "0x1", Amount: 1, Confirmed: true},
+		{Date: Time(day.AddDate(0, 0, 1)), TxHash: "0x2", Amount: 1, Confirmed: true},
+		{Date: Time(day.AddDate(0, 0, 2)), TxHash: "0x3", Amount: 1, Confirmed: true},
+	}
+	sale := day.AddDate(0, 0, 3)
+	tests := []struct {
+		name      string
+		method    CostBasisMethod
+		disposals []Disposal
+		gains     []float64
+		remaining []float64
+	}{
+		{"fifo", FIFO, []Disposal{{Date: sale, Amount: 1.5, Proceeds: 600}}, []float64{300, 100}, []float64{0, 0.5, 1}},
+		{"lifo", LIFO, []Disposal{{Date: sale, Amount: 1.5, Proceeds: 600}}, []float64{100, 100}, []float64{1, 0.5, 0}},
+		{"partial lot", FIFO, []Disposal{{Date: sale, Amount: 0.25, Proceeds: 100}}, []float64{75}, []float64{0.75, 1, 1}},
+		{"lots acquired later", LIFO, []Disposal{{Date: day.AddDate(0, 0, 1), Amount: 2, Proceeds: 400}}, []float64{0, 100}, []float64{0, 0, 1}},
+	}
+	for _, test := range tests {
+		report, err := NewTaxReport(payments, prices, USD, 0, test.method, test.disposals, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(report.Gains) != len(test.gains) {
+			t.Fatalf("%s: expected %d gains, got %+v", test.name, len(test.gains), report.Gains)
+		}
+		for i, g := range report.Gains {
+			if math.Abs(g.Gain-test.gains[i]) > 1e-9 {
+				t.Errorf("%s: gain %d: expected %f, got %f", test.name, i, test.gains[i], g.Gain)
+			}
+		}
+		for i, l := range report.Lots {
+			if math.Abs(l.Remaining-test.remaining[i]) > 1e-9 {
+				t.Errorf("%s: lot %d: expected %f remaining, got %f", test.name, i, test.remaining[i], l.Remaining)
+			}
+		}
+	}
+	if _, err := NewTaxReport(payments, prices, USD, 0, FIFO, []Disposal{{Date: sale, Amount: 3.5, Proceeds: 1000}}, time.UTC); err == nil {
+		t.Error("expected an error disposing of more than the available lots")
+	}
+	if _, err := NewTaxReport(payments, prices, USD, 0, FIFO, []Disposal{{Date: day.AddDate(0, 0, 1), Amount: 2.5, Proceeds: 1000}}, time.UTC); err == nil {
+		t.Error("expected an error disposing of lots acquired after the disposal")
+	}
+}
+
+func TestValuePaymentsStaleness(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	prices := PriceHistory{{Date: day, Prices: PriceReport{USDollar: 100}}}
+	payments := []Payment{{Date: Time(day.AddDate(0, 0, 3)), TxHash: "0x1", Amount: 1, Confirmed: true}}
+	if _, err := ValuePayments(payments, prices, USD, 24*time.Hour); err == nil {
+		t.Error("expected an error valuing a payment three days after the last price")
+	}
+	valued, err := ValuePayments(payments, prices, USD, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valued) != 1 || valued[0].Value != 100 || valued[0].Staleness != 72*time.Hour {
+		t.Errorf("unexpected valuation %+v", valued)
+	}
+}
+
+func TestTaxReportYears(t *testing.T) {
+	newYear := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	prices := PriceHistory{{Date: newYear, Prices: PriceReport{USDollar: 100}}}
+	payments := []Payment{{Date: Time(newYear.Add(-2 * time.Hour)), TxHash: "0x1", Amount: 1, Confirmed: true}}
+	tests := []struct {
+		loc  *time.Location
+		year int
+	}{
+		{time.UTC, 2017},
+		{time.FixedZone("UTC+3", 3*60*60), 2018},
+	}
+	for _, test := range tests {
+		report, err := NewTaxReport(payments, prices, USD, 0, FIFO, nil, test.loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Years) != 1 || report.Years[0].Year != test.year {
+			t.Errorf("%s: expected the payment in %d, got %+v", test.loc, test.year, report.Years)
+		}
+	}
+}
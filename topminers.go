@@ -0,0 +1,158 @@
+package npapi
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MinerRank stores the position of a miner in a top-miner snapshot.
+type MinerRank struct {
+	// Miner address
+	Address string
+	// Miner hashrate [MH/s]
+	Hashrate float64
+	// Rank, starting at one
+	Rank int
+	// Rank in the previous snapshot, zero if the miner was not ranked
+	PreviousRank int
+	// Share of the pool hashrate
+	Share float64
+}
+
+// RankingSnapshot stores the top miners at a specific point in time.
+type RankingSnapshot struct {
+	// Snapshot date
+	Date time.Time
+	// Pool hashrate [MH/s]
+	PoolHashrate float64
+	// Ranked miners, ordered by rank
+	Miners []MinerRank
+}
+
+// RankOf returns the rank a miner with the given hashrate [MH/s] would have in the snapshot.
+// A rank beyond the number of ranked miners means it would not be listed.
+func (s RankingSnapshot) RankOf(hashrate float64) int {
+	for _, m := range s.Miners {
+		if hashrate > m.Hashrate {
+			return m.Rank
+		}
+	}
+	return len(s.Miners) + 1
+}
+
+// Find returns the rank of the address in the snapshot.
+func (s RankingSnapshot) Find(addr string) (MinerRank, bool) {
+	for _, m := range s.Miners {
+		if strings.EqualFold(m.Address, addr) {
+			return m, true
+		}
+	}
+	return MinerRank{}, false
+}
+
+// RankingChange describes the difference between two successive snapshots.
+type RankingChange struct {
+	// Current snapshot, with previous ranks filled in
+	Snapshot RankingSnapshot
+	// Addresses that entered the ranking
+	Entered []string
+	// Miners that left the ranking, as ranked in the previous snapshot
+	Exited []MinerRank
+}
+
+// RankTracker records successive top-miner snapshots.
+type RankTracker struct {
+	// Recorded snapshots, ordered by date
+	Snapshots []RankingSnapshot
+}
+
+// LoadRankTracker reads a tracker previously stored using Save.
+func LoadRankTracker(path string) (*RankTracker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	tracker := &RankTracker{}
+	if err := json.NewDecoder(file).Decode(tracker); err != nil {
+		return nil, err
+	}
+	return tracker, nil
+}
+
+// Save stores the tracker in the given file.
+func (t *RankTracker) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(t); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Latest returns the most recent snapshot.
+func (t *RankTracker) Latest() (RankingSnapshot, bool) {
+	if len(t.Snapshots) == 0 {
+		return RankingSnapshot{}, false
+	}
+	return t.Snapshots[len(t.Snapshots)-1], true
+}
+
+// Add records a snapshot of the top miners and returns the changes to the previous snapshot.
+func (t *RankTracker) Add(date time.Time, miners []User, poolHashrate float64) RankingChange {
+	sorted := append([]User(nil), miners...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Hashrate > sorted[j].Hashrate })
+	previous, hasPrevious := t.Latest()
+	snapshot := RankingSnapshot{Date: date, PoolHashrate: poolHashrate, Miners: make([]MinerRank, len(sorted))}
+	var change RankingChange
+	for i, m := range sorted {
+		rank := MinerRank{Address: m.Address, Hashrate: m.Hashrate, Rank: i + 1}
+		if poolHashrate > 0 {
+			rank.Share = m.Hashrate / poolHashrate
+		}
+		if p, ok := previous.Find(m.Address); ok {
+			rank.PreviousRank = p.Rank
+		} else if hasPrevious {
+			change.Entered = append(change.Entered, m.Address)
+		}
+		snapshot.Miners[i] = rank
+	}
+	for _, p := range previous.Miners {
+		if _, ok := snapshot.Find(p.Address); !ok {
+			change.Exited = append(change.Exited, p)
+		}
+	}
+	change.Snapshot = snapshot
+	t.Snapshots = append(t.Snapshots, snapshot)
+	return change
+}
+
+// Record fetches the top miners and pool hashrate and adds them as a new snapshot.
+func (t *RankTracker) Record() (RankingChange, error) {
+	miners, err := TopMiners()
+	if err != nil {
+		return RankingChange{}, err
+	}
+	hashrate, err := PoolHashrate()
+	if err != nil {
+		return RankingChange{}, err
+	}
+	return t.Add(time.Now(), miners, hashrate), nil
+}
+
+// History returns the rank of the address in every snapshot, zero where it was not ranked.
+func (t *RankTracker) History(addr string) []int {
+	ranks := make([]int, len(t.Snapshots))
+	for i, s := range t.Snapshots {
+		if m, ok := s.Find(addr); ok {
+			ranks[i] = m.Rank
+		}
+	}
+	return ranks
+}
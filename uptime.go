@@ -0,0 +1,235 @@
+package npapi
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// ShareInterval is the size of the buckets share histories are reported in.
+const ShareInterval = 10 * time.Minute
+
+// ShareHistoryWindow is the period served by the share history endpoints.
+const ShareHistoryWindow = 24 * time.Hour
+
+// Outage is an interval in which a worker submitted no shares.
+type Outage struct {
+	From, To time.Time
+	// Resolved is false if the outage lasted until the end of the period or into a gap of the history
+	Resolved bool
+}
+
+// Duration returns the length of the outage.
+func (o Outage) Duration() time.Duration {
+	return o.To.Sub(o.From)
+}
+
+// UptimeReport stores the availability of a worker in a period.
+type UptimeReport struct {
+	// Worker ID
+	Worker string
+	// Reporting period
+	From, To time.Time
+	// Part of the period covered by the share history
+	Covered time.Duration
+	// Part of the period before the share history starts, after it was retrieved or within its gaps
+	Unknown time.Duration
+	// Time spent online and offline within the covered part
+	Online, Offline time.Duration
+	// Fraction of the covered part spent online
+	Availability float64
+	// Outages, ordered by date
+	Outages []Outage
+	// Longest outage
+	LongestOutage time.Duration
+	// Mean time to recovery of all resolved outages
+	MTTR time.Duration
+}
+
+// Uptime derives the availability of a worker in the period [from, to) from its share history, retrieved at
+// until. A share bucket counts as online if it contains shares or the last share of the worker; missing buckets
+// count as offline. The history is only known from its first bucket until its retrieval, the rest of the period
+// is reported as unknown and neither counts as online nor offline. If maxGap is positive, the history is assumed
+// to be assembled from windows of that length, so missing buckets in gaps longer than maxGap, e.g. while the
+// history was not polled, are reported as unknown as well. This also applies to long offline periods.
+func Uptime(worker string, shares []ShareItem, lastShare, until, from, to time.Time, maxGap time.Duration) UptimeReport {
+	report := UptimeReport{Worker: worker, From: from, To: to}
+	if !to.After(from) {
+		return report
+	}
+	online := make(map[int64]bool)
+	var known []ShareItem
+	for _, s := range shares {
+		known = append(known, ShareItem{Date: Time(time.Time(s.Date).Truncate(ShareInterval)), Shares: s.Shares})
+		if s.Shares > 0 {
+			online[time.Time(s.Date).Truncate(ShareInterval).Unix()] = true
+		}
+	}
+	if !lastShare.IsZero() {
+		known = append(known, ShareItem{Date: Time(lastShare.Truncate(ShareInterval)), Shares: 1})
+		online[lastShare.Truncate(ShareInterval).Unix()] = true
+	}
+	sort.Slice(known, func(i, j int) bool { return time.Time(known[i].Date).Before(time.Time(known[j].Date)) })
+	// clip the period to the covered part
+	start, end := from, to
+	if len(known) > 0 && time.Time(known[0].Date).After(start) {
+		start = time.Time(known[0].Date)
+	}
+	if until.Before(end) {
+		end = until
+	}
+	if len(known) == 0 || !end.After(start) {
+		report.Unknown = to.Sub(from)
+		return report
+	}
+	var gaps []SeriesGap
+	if maxGap > 0 {
+		gaps = ShareGaps(known, maxGap)
+	}
+	unknown := func(bucket time.Time) bool {
+		for _, g := range gaps {
+			if bucket.After(g.From) && bucket.Before(g.To) {
+				return true
+			}
+		}
+		return false
+	}
+	var current *Outage
+	for bucket := start.Truncate(ShareInterval); bucket.Before(end); bucket = bucket.Add(ShareInterval) {
+		bucketStart, bucketEnd := bucket, bucket.Add(ShareInterval)
+		if bucketStart.Before(start) {
+			bucketStart = start
+		}
+		if bucketEnd.After(end) {
+			bucketEnd = end
+		}
+		if unknown(bucket) {
+			// the outage may have been resolved within the gap
+			if current != nil {
+				report.Outages = append(report.Outages, *current)
+				current = nil
+			}
+			continue
+		}
+		report.Covered += bucketEnd.Sub(bucketStart)
+		if online[bucket.Unix()] {
+			report.Online += bucketEnd.Sub(bucketStart)
+			if current != nil {
+				current.Resolved = true
+				report.Outages = append(report.Outages, *current)
+				current = nil
+			}
+			continue
+		}
+		report.Offline += bucketEnd.Sub(bucketStart)
+		if current == nil {
+			current = &Outage{From: bucketStart}
+		}
+		current.To = bucketEnd
+	}
+	if current != nil {
+		report.Outages = append(report.Outages, *current)
+	}
+	report.Unknown = to.Sub(from) - report.Covered
+	if report.Covered == 0 {
+		return report
+	}
+	report.Availability = float64(report.Online) / float64(report.Covered)
+	var recovery time.Duration
+	var resolved int
+	for _, o := range report.Outages {
+		if o.Duration() > report.LongestOutage {
+			report.LongestOutage = o.Duration()
+		}
+		if o.Resolved {
+			recovery += o.Duration()
+			resolved++
+		}
+	}
+	if resolved > 0 {
+		report.MTTR = recovery / time.Duration(resolved)
+	}
+	return report
+}
+
+// WorkersUptime fetches the share history of all workers and derives their availability in the period [from, to).
+// The API only serves a short window of share history, so most of a longer period will be unknown;
+// use Store.WorkersUptime to cover longer periods.
+func WorkersUptime(addr string, from, to time.Time) ([]UptimeReport, error) {
+	workers, err := Workers(addr)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	reports := make([]UptimeReport, len(workers))
+	for i, w := range workers {
+		shares, err := WorkerShareHistory(addr, w.ID)
+		if err != nil {
+			return nil, err
+		}
+		reports[i] = Uptime(w.ID, shares, time.Time(w.LastShare), now, from, to, 0)
+	}
+	return reports, nil
+}
+
+// WorkersUptime backfills the share history of all workers into the store and derives their availability in
+// the period [from, to) from the stored series. Calling it periodically, at least once per share history window,
+// keeps the whole period covered; gaps in the stored series longer than ShareHistoryWindow are reported as unknown.
+// Workers no longer listed by the pool are reported from their stored series, as offline after their last share.
+func (s *Store) WorkersUptime(addr string, from, to time.Time) ([]UptimeReport, error) {
+	workers, err := Workers(addr)
+	if err != nil {
+		return nil, err
+	}
+	lastShares := make(map[string]time.Time, len(workers))
+	for _, w := range workers {
+		if _, err := s.BackfillWorkerShares(addr, w.ID, from, KeepMax); err != nil {
+			return nil, err
+		}
+		lastShares[w.ID] = time.Time(w.LastShare)
+	}
+	return s.storedUptime(addr, lastShares, time.Now(), from, to)
+}
+
+// storedUptime derives the availability of the workers with a stored share history and the listed workers, given
+// with their last share, from the store.
+func (s *Store) storedUptime(addr string, lastShares map[string]time.Time, until, from, to time.Time) ([]UptimeReport, error) {
+	ids, err := s.Workers(addr)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		stored[id] = true
+	}
+	// workers without shares in the fetched window have no stored series yet
+	for id := range lastShares {
+		if !stored[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	reports := make([]UptimeReport, len(ids))
+	for i, id := range ids {
+		// the stored series is read from its start, so that the coverage starts with the first stored bucket
+		shares, err := s.ShareHistory(WorkerSharesSeries(addr, id), time.Time{}, to)
+		if err != nil {
+			return nil, err
+		}
+		reports[i] = Uptime(id, shares, lastShares[id], until, from, to, ShareHistoryWindow)
+	}
+	return reports, nil
+}
+
+// WriteUptimeReports renders the reports as a table.
+func WriteUptimeReports(w io.Writer, reports []UptimeReport) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "WORKER\tAVAILABILITY\tONLINE\tOFFLINE\tUNKNOWN\tOUTAGES\tLONGEST\tMTTR")
+	for _, r := range reports {
+		fmt.Fprintf(table, "%s\t%.2f%%\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			r.Worker, r.Availability*100, r.Online, r.Offline, r.Unknown, len(r.Outages), r.LongestOutage, r.MTTR)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,90 @@
+package npapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUptime(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	var shares []ShareItem
+	for bucket := day; bucket.Before(day.Add(24 * time.Hour)); bucket = bucket.Add(ShareInterval) {
+		offset := bucket.Sub(day)
+		if offset >= 10*time.Hour && offset < 11*time.Hour {
+			continue
+		}
+		item := ShareItem{Date: Time(bucket), Shares: 5}
+		if offset >= 20*time.Hour && offset < 20*time.Hour+30*time.Minute {
+			item.Shares = 0
+		}
+		shares = append(shares, item)
+	}
+	lastShare := day.Add(24*time.Hour - 5*time.Minute)
+	until := day.Add(24*time.Hour + 20*time.Minute)
+	tests := []struct {
+		name                      string
+		from, to                  time.Time
+		covered, unknown, offline time.Duration
+		outages                   int
+		unresolved                bool
+		longest, mttr             time.Duration
+	}{
+		{"month", day.AddDate(0, 0, -29), day.Add(24 * time.Hour), 24 * time.Hour, 29 * 24 * time.Hour, 90 * time.Minute,
+			2, false, time.Hour, 45 * time.Minute},
+		{"clipped", day.Add(10*time.Hour + 5*time.Minute), day.Add(25 * time.Hour), 14*time.Hour + 15*time.Minute, 40 * time.Minute,
+			105 * time.Minute, 3, true, 55 * time.Minute, 42*time.Minute + 30*time.Second},
+	}
+	for _, test := range tests {
+		r := Uptime("rig1", shares, lastShare, until, test.from, test.to, 0)
+		if r.Covered != test.covered || r.Unknown != test.unknown || r.Offline != test.offline {
+			t.Errorf("%s: expected %s covered, %s unknown and %s offline, got %s, %s and %s",
+				test.name, test.covered, test.unknown, test.offline, r.Covered, r.Unknown, r.Offline)
+		}
+		if r.Online+r.Offline != r.Covered {
+			t.Errorf("%s: online and offline time do not add up to the covered time", test.name)
+		}
+		if len(r.Outages) != test.outages || r.Outages[len(r.Outages)-1].Resolved == test.unresolved {
+			t.Errorf("%s: unexpected outages %+v", test.name, r.Outages)
+		}
+		if r.LongestOutage != test.longest || r.MTTR != test.mttr {
+			t.Errorf("%s: expected longest outage %s and MTTR %s, got %s and %s", test.name, test.longest, test.mttr, r.LongestOutage, r.MTTR)
+		}
+		if want := float64(r.Online) / float64(test.covered); r.Availability != want {
+			t.Errorf("%s: expected availability %f, got %f", test.name, want, r.Availability)
+		}
+	}
+	if r := Uptime("rig2", nil, time.Time{}, until, day, day.Add(24*time.Hour), 0); r.Covered != 0 || r.Unknown != 24*time.Hour {
+		t.Errorf("expected an unknown period without share history, got %+v", r)
+	}
+}
+
+func TestUptimePollingGap(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	var shares []ShareItem
+	for bucket := day; bucket.Before(day.Add(72 * time.Hour)); bucket = bucket.Add(ShareInterval) {
+		// the store was not polled on the second day, and the worker was offline for an hour on the third
+		offset := bucket.Sub(day)
+		if offset >= 12*time.Hour && offset < 60*time.Hour || offset >= 66*time.Hour && offset < 67*time.Hour {
+			continue
+		}
+		shares = append(shares, ShareItem{Date: Time(bucket), Shares: 5})
+	}
+	until := day.Add(72 * time.Hour)
+	r := Uptime("rig1", shares, time.Time{}, until, day, until, ShareHistoryWindow)
+	gap := 48 * time.Hour
+	if r.Unknown != gap || r.Offline != time.Hour || r.Covered != 72*time.Hour-gap {
+		t.Errorf("expected %s unknown and an hour offline, got %s unknown and %s offline", gap, r.Unknown, r.Offline)
+	}
+	if len(r.Outages) != 1 || !r.Outages[0].Resolved {
+		t.Errorf("expected the polling gap not to count as an outage, got %+v", r.Outages)
+	}
+	if r := Uptime("rig1", shares, time.Time{}, until, day, until, 0); r.Unknown != 0 || r.Offline != gap+time.Hour {
+		t.Errorf("expected the gap to count as offline without a window, got %s unknown and %s offline", r.Unknown, r.Offline)
+	}
+	for i := 66; i < 72; i++ {
+		shares[i].Shares = 0
+	}
+	if r := Uptime("rig1", shares, time.Time{}, until, day, until, ShareHistoryWindow); len(r.Outages) != 2 || r.Outages[0].Resolved {
+		t.Errorf("expected an outage running into the gap to be unresolved, got %+v", r.Outages)
+	}
+}
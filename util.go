@@ -14,7 +14,11 @@ type jsonResponse struct {
 }
 
 func fetch(data interface{}, b string, params ...interface{}) error {
-	components := append([]interface{}{apiAddress}, params...)
+	return fetchFrom(apiAddress, data, b, params...)
+}
+
+func fetchFrom(base string, data interface{}, b string, params ...interface{}) error {
+	components := append([]interface{}{base}, params...)
 	url := fmt.Sprintf(b, components...)
 	resp, err := http.Get(url)
 	if err != nil {